@@ -3,39 +3,291 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/config"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/holidays"
 	"github.com/hpcsc/vietnamese-lunar-calendar/internal/ics"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/importer"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
 )
 
 var (
 	yearsAhead   = flag.Int("years", 10, "Number of years ahead to generate")
 	outputFile   = flag.String("output", "vietnamese-lunar-calendar.ics", "Output ICS file path")
-	customEvents = flag.String("events", "", "Custom lunar events in format 'day/month:title' (recurring) or 'day/month/year:title' (single year), can be repeated")
-	timezone     = flag.String("timezone", "Asia/Hanoi", "Timezone for lunar date calculation")
+	configFile   = flag.String("config", "", "YAML or JSON file describing custom events")
+	customEvents = flag.String("events", "", "Custom lunar events in format 'day/month:title[:description]' (recurring) or 'day/month/year:title[:description]' (single year), comma separated; last/month is the last day of the month, {name} anchors on another event or a solar term +Nd/-Nd moves the date and +N makes the event last N days; quote or backslash-escape titles containing , or :")
+	canChi       = flag.Bool("canchi", false, "Include Can Chi (sexagenary) names in event summaries and descriptions")
+	solarTerms   = flag.Bool("solar-terms", false, "Include the 24 solar terms (tiết khí), e.g. Thanh Minh and Đông Chí")
+	ram          = flag.Bool("ram", false, "Include Rằm (the 15th day) of every lunar month")
+	moonPhases   = flag.Bool("moon-phases", false, "Include timed events at each new moon and full moon")
+	timezone     = flag.String("timezone", "Asia/Hanoi", "Timezone for lunar date calculation, e.g. Asia/Shanghai for the Chinese calendar")
+	include      = flag.String("include", "", "Comma separated IDs of the only built-in festivals to generate: "+strings.Join(calendar.FestivalIDs, ", "))
+	exclude      = flag.String("exclude", "", "Comma separated IDs of built-in festivals to leave out, e.g. mung-1")
+	packs        = flag.String("packs", "", "Comma separated festival packs to add to the core festivals: "+strings.Join(calendar.Packs[1:], ", "))
+	lang         = flag.String("lang", calendar.Vietnamese, "Language of the festival descriptions: vi or en")
+	gio          = flag.String("gio", "", "Death anniversaries (giỗ) in format 'yyyy-mm-dd:name' (solar date of death) or 'day/month/year:name' (lunar date of death)")
+	missingDate  = flag.String("missing-date", "skip", "What to do with custom events on day 30 of a 29-day month: skip, last, next or error")
+	holidayMode  = flag.Bool("holidays", false, "Generate the official public holidays and days off (nghỉ bù) instead of the lunar calendar")
+	overrides    = flag.String("holiday-overrides", "", "YAML or JSON file of government-announced days off replacing the computed ones, used with -holidays")
 )
 
+// alarmFlags collects the repeated -alarm flags.
+type alarmFlags []string
+
+func (a *alarmFlags) String() string {
+	return strings.Join(*a, ", ")
+}
+
+func (a *alarmFlags) Set(value string) error {
+	*a = append(*a, value)
+	return nil
+}
+
+var alarms alarmFlags
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		importEvents(os.Args[2:])
+		return
+	}
+
+	flag.Var(&alarms, "alarm", "Alarm for the events without their own, e.g. 1d, 3d@08:00 or morning; prefix a category to limit it to its events, e.g. Giỗ=3d@08:00 (repeatable)")
 	flag.Parse()
 
+	if _, err := lunar.LoadLocation(*timezone); err != nil {
+		log.Fatalf("Invalid timezone: %v", err)
+	}
+
+	policy, err := calendar.ParseMissingDatePolicy(*missingDate)
+	if err != nil {
+		log.Fatalf("Invalid missing date policy: %v", err)
+	}
+
 	startYear := time.Now().Year()
 
-	gen := calendar.NewGenerator(startYear, *yearsAhead, *timezone)
-	events, err := gen.Generate(*customEvents)
+	if *holidayMode {
+		generateHolidays(startYear)
+		return
+	}
+
+	anniversaries, err := calendar.ParseAnniversaries(*gio)
 	if err != nil {
-		log.Fatalf("Failed to generate events: %v", err)
+		log.Fatalf("Invalid anniversaries: %v", err)
 	}
 
-	icsContent := ics.Generate(events)
+	genOpts := []calendar.Option{
+		calendar.WithMissingDatePolicy(policy),
+		calendar.WithAnniversaries(anniversaries...),
+	}
+	includeIDs, err := calendar.ParseFestivalIDs(*include)
+	if err != nil {
+		log.Fatalf("Invalid -include: %v", err)
+	}
+	excludeIDs, err := calendar.ParseFestivalIDs(*exclude)
+	if err != nil {
+		log.Fatalf("Invalid -exclude: %v", err)
+	}
+	packIDs, err := calendar.ParseFestivalPacks(*packs)
+	if err != nil {
+		log.Fatalf("Invalid -packs: %v", err)
+	}
+	if *lang != calendar.Vietnamese && *lang != calendar.English {
+		log.Fatalf("Invalid -lang: %q, expected vi or en", *lang)
+	}
+	genOpts = append(genOpts,
+		calendar.WithFestivals(includeIDs...),
+		calendar.WithoutFestivals(excludeIDs...),
+		calendar.WithFestivalPacks(packIDs...),
+		calendar.WithLanguage(*lang),
+	)
+	if *configFile != "" {
+		cfg, err := config.Load(*configFile)
+		if err != nil {
+			log.Fatalf("Invalid config: %v", err)
+		}
+		genOpts = append(genOpts, calendar.WithRules(cfg.Events...))
+		for category, categoryAlarms := range cfg.Alarms {
+			if category == "*" {
+				category = ""
+			}
+			genOpts = append(genOpts, calendar.WithCategoryAlarms(category, categoryAlarms...))
+		}
+	}
+	for _, spec := range alarms {
+		category, alarm, err := calendar.ParseCategoryAlarm(spec)
+		if err != nil {
+			log.Fatalf("Invalid -alarm: %v", err)
+		}
+		genOpts = append(genOpts, calendar.WithCategoryAlarms(category, alarm))
+	}
+	if *solarTerms {
+		genOpts = append(genOpts, calendar.WithSolarTerms())
+	}
+	if *moonPhases {
+		genOpts = append(genOpts, calendar.WithMoonPhases())
+	}
+	if *ram {
+		genOpts = append(genOpts, calendar.WithFullMoonDays())
+	}
 
-	err = os.WriteFile(*outputFile, []byte(icsContent), 0644)
+	gen := calendar.NewGenerator(startYear, *yearsAhead, *timezone, genOpts...)
+	events, err := gen.Events(*customEvents)
 	if err != nil {
+		log.Fatalf("Failed to generate events: %v", err)
+	}
+	for _, w := range gen.Warnings() {
+		log.Printf("Warning: %s", w)
+	}
+
+	icsOpts := timestampOptions()
+	if *canChi {
+		icsOpts = append(icsOpts, ics.WithCanChi())
+	}
+
+	count := 0
+	counted := func(yield func(calendar.Event) bool) {
+		for e := range events {
+			count++
+			if !yield(e) {
+				return
+			}
+		}
+	}
+	if err := writeFile(*outputFile, func(w io.Writer) error {
+		return ics.NewEncoder(w, icsOpts...).Encode(counted)
+	}); err != nil {
 		log.Fatalf("Failed to write ICS file: %v", err)
 	}
 
 	fmt.Printf("Generated ICS file with %d events for years %d-%d to %s\n",
-		len(events), startYear, startYear+*yearsAhead, *outputFile)
+		count, startYear, startYear+*yearsAhead, *outputFile)
+}
+
+// writeFile creates the file and writes it with write, closing it in all
+// cases.
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func generateHolidays(startYear int) {
+	var announced []holidays.Override
+	if *overrides != "" {
+		var err error
+		if announced, err = config.LoadHolidayOverrides(*overrides); err != nil {
+			log.Fatalf("Invalid holiday overrides: %v", err)
+		}
+	}
+
+	var days []holidays.Holiday
+	for year := startYear; year < startYear+*yearsAhead; year++ {
+		forYear, err := holidays.ForYear(year, announced...)
+		if err != nil {
+			log.Fatalf("Failed to generate holidays: %v", err)
+		}
+		days = append(days, forYear...)
+	}
+
+	err := os.WriteFile(*outputFile, []byte(ics.GenerateHolidays(days, timestampOptions()...)), 0644)
+	if err != nil {
+		log.Fatalf("Failed to write ICS file: %v", err)
+	}
+
+	fmt.Printf("Generated ICS file with %d holidays for years %d-%d to %s\n",
+		len(days), startYear, startYear+*yearsAhead, *outputFile)
+}
+
+// importEvents runs the import command, which converts the events of an ICS
+// or CSV file on solar dates to custom events recurring on the lunar date of
+// their original day:
+//
+//	main.go import [-format config|events] [-timezone tz] [-output file] birthdays.ics
+func importEvents(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "config", "Output format: config for a -config file, or events for the -events flag")
+	tz := fs.String("timezone", "Asia/Hanoi", "Timezone of the lunar dates")
+	output := fs.String("output", "", "Output file path, standard output by default")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: import [flags] file.ics|file.csv")
+		fmt.Fprintln(fs.Output(), "CSV rows are date,title[,description] with the date as yyyy-mm-dd.")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if _, err := lunar.LoadLocation(*tz); err != nil {
+		log.Fatalf("Invalid timezone: %v", err)
+	}
+
+	path := fs.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to read events: %v", err)
+	}
+	defer f.Close()
+
+	read := importer.ReadICS
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		read = importer.ReadCSV
+	}
+	events, err := read(f)
+	if err != nil {
+		log.Fatalf("Invalid events in %s: %v", path, err)
+	}
+	rules := importer.Rules(events, *tz)
+
+	var content []byte
+	switch *format {
+	case "config":
+		content, err = config.Marshal(config.Config{Events: rules})
+	case "events":
+		var s string
+		s, err = calendar.FormatEvents(rules)
+		content = []byte(s + "\n")
+	default:
+		log.Fatalf("Invalid format %q, expected config or events", *format)
+	}
+	if err != nil {
+		log.Fatalf("Failed to convert events: %v", err)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(content)
+		return
+	}
+	if err := os.WriteFile(*output, content, 0644); err != nil {
+		log.Fatalf("Failed to write events: %v", err)
+	}
+	fmt.Printf("Converted %d events to %s\n", len(rules), *output)
+}
+
+// timestampOptions sets DTSTAMP to SOURCE_DATE_EPOCH when it is set, see
+// https://reproducible-builds.org/specs/source-date-epoch/.
+func timestampOptions() []ics.Option {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid SOURCE_DATE_EPOCH %q, expected a number of seconds", epoch)
+	}
+	return []ics.Option{ics.WithTimestamp(time.Unix(seconds, 0))}
 }
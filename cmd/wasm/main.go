@@ -1,37 +1,37 @@
 package main
 
 import (
+	"strconv"
+	"strings"
 	"syscall/js"
 	"time"
 
 	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
 	"github.com/hpcsc/vietnamese-lunar-calendar/internal/ics"
-	"github.com/hungtrd/amlich"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
 )
 
-func getTimezoneOffset(tz string, year, month, day int) int {
-	loc, err := time.LoadLocation(tz)
-	if err != nil {
-		return 7
-	}
-	t := time.Date(year, time.Month(month), day, 12, 0, 0, 0, loc)
-	_, offset := t.Zone()
-	return offset / 3600
-}
-
 func convertSolarToLunar(this js.Value, args []js.Value) interface{} {
 	year := args[0].Int()
 	month := args[1].Int()
 	day := args[2].Int()
 	timezone := args[3].String()
 
-	tz := getTimezoneOffset(timezone, year, int(month), day)
-	lunarDay, lunarMonth, lunarYear, _ := amlich.Solar2Lunar(day, month, year, tz)
+	solar := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	lunarYear, ld := lunar.FromSolar(solar, lunar.WithTimezone(timezone))
+	canChi := lunar.SexagenaryOf(lunarYear, ld, solar)
 
 	return map[string]interface{}{
-		"day":   lunarDay,
-		"month": lunarMonth,
+		"day":   ld.Day,
+		"month": ld.Month,
 		"year":  lunarYear,
+		"leap":  ld.Leap,
+		"canChi": map[string]interface{}{
+			"year":  canChi.Year.String(),
+			"month": canChi.Month.String(),
+			"day":   canChi.Day.String(),
+			"text":  canChi.String(),
+		},
 	}
 }
 
@@ -40,14 +40,19 @@ func convertLunarToSolar(this js.Value, args []js.Value) interface{} {
 	month := args[1].Int()
 	day := args[2].Int()
 	timezone := args[3].String()
+	leap := len(args) > 4 && args[4].Truthy()
 
-	tz := getTimezoneOffset(timezone, year, int(month), day)
-	solarDay, solarMonth, solarYear := amlich.Lunar2Solar(day, month, year, 0, tz)
+	date := lunar.ToSolar(year, lunar.Date{Day: day, Month: month, Leap: leap}, lunar.WithTimezone(timezone))
+	if date.IsZero() {
+		return map[string]interface{}{
+			"error": "lunar date does not exist in this year",
+		}
+	}
 
 	return map[string]interface{}{
-		"year":  solarYear,
-		"month": solarMonth,
-		"day":   solarDay,
+		"year":  date.Year(),
+		"month": int(date.Month()),
+		"day":   date.Day(),
 	}
 }
 
@@ -55,20 +60,138 @@ func generateICS(this js.Value, args []js.Value) interface{} {
 	yearsAhead := args[0].Int()
 	customEvents := args[1].String()
 	timezone := args[2].String()
+	canChi := len(args) > 3 && args[3].Truthy()
+	solarTerms := len(args) > 4 && args[4].Truthy()
+	moonPhases := len(args) > 5 && args[5].Truthy()
+	missingDate := "skip"
+	if len(args) > 6 && args[6].Truthy() {
+		missingDate = args[6].String()
+	}
+	gio := ""
+	if len(args) > 7 && args[7].Truthy() {
+		gio = args[7].String()
+	}
+	exclude := ""
+	if len(args) > 8 && args[8].Truthy() {
+		exclude = args[8].String()
+	}
+	ram := len(args) > 9 && args[9].Truthy()
+	packs := ""
+	if len(args) > 10 && args[10].Truthy() {
+		packs = args[10].String()
+	}
+	include := ""
+	if len(args) > 11 && args[11].Truthy() {
+		include = args[11].String()
+	}
+	lang := calendar.Vietnamese
+	if len(args) > 12 && args[12].Truthy() {
+		lang = args[12].String()
+	}
+
+	if _, err := lunar.LoadLocation(timezone); err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	policy, err := calendar.ParseMissingDatePolicy(missingDate)
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	anniversaries, err := calendar.ParseAnniversaries(gio)
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	excludeIDs, err := calendar.ParseFestivalIDs(exclude)
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	packIDs, err := calendar.ParseFestivalPacks(packs)
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	includeIDs, err := calendar.ParseFestivalIDs(include)
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	if lang != calendar.Vietnamese && lang != calendar.English {
+		return map[string]interface{}{
+			"error": "invalid language " + strconv.Quote(lang) + ", expected vi or en",
+		}
+	}
 
 	startYear := time.Now().Year()
-	gen := calendar.NewGenerator(startYear, yearsAhead, timezone)
-	events, err := gen.Generate(customEvents)
+	genOpts := []calendar.Option{
+		calendar.WithMissingDatePolicy(policy),
+		calendar.WithAnniversaries(anniversaries...),
+		calendar.WithoutFestivals(excludeIDs...),
+		calendar.WithFestivalPacks(packIDs...),
+		calendar.WithFestivals(includeIDs...),
+		calendar.WithLanguage(lang),
+	}
+	if solarTerms {
+		genOpts = append(genOpts, calendar.WithSolarTerms())
+	}
+	if moonPhases {
+		genOpts = append(genOpts, calendar.WithMoonPhases())
+	}
+	if ram {
+		genOpts = append(genOpts, calendar.WithFullMoonDays())
+	}
+
+	gen := calendar.NewGenerator(startYear, yearsAhead, timezone, genOpts...)
+	events, err := gen.Events(customEvents)
 	if err != nil {
 		return map[string]interface{}{
 			"error": err.Error(),
 		}
 	}
 
-	icsContent := ics.Generate(events)
+	var icsOpts []ics.Option
+	if canChi {
+		icsOpts = append(icsOpts, ics.WithCanChi())
+	}
+
+	var warnings []interface{}
+	for _, w := range gen.Warnings() {
+		warnings = append(warnings, w.String())
+	}
+
+	count := 0
+	counted := func(yield func(calendar.Event) bool) {
+		for e := range events {
+			count++
+			if !yield(e) {
+				return
+			}
+		}
+	}
+	var content strings.Builder
+	if err := ics.NewEncoder(&content, icsOpts...).Encode(counted); err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
 	return map[string]interface{}{
-		"content": icsContent,
-		"count":   len(events),
+		"content":  content.String(),
+		"count":    count,
+		"warnings": warnings,
 	}
 }
 
@@ -0,0 +1,140 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Alarm is a reminder Before the start of an event. A negative Before goes
+// off after the start, e.g. at 08:00 on the day of an all-day event.
+type Alarm struct {
+	Before time.Duration
+}
+
+// morning is the time of day of a "morning" alarm.
+const morning = 8 * time.Hour
+
+// ParseAlarm parses when an alarm goes off: how long before an event, as a
+// number of days, hours and minutes, e.g. "1d", "2h30m" or "0" for the start
+// of the event; a number of days before an all-day event at a time of day,
+// e.g. "3d@08:00", or "@07:30" on the day itself; or "morning" for 08:00 on
+// the day.
+func ParseAlarm(s string) (Alarm, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Alarm{}, errors.New("invalid alarm: alarm cannot be empty")
+	}
+	invalid := errors.New("invalid alarm: " + s + ", expected e.g. 1d, 2h, 1d12h, 3d@08:00 or morning")
+	switch s {
+	case "0":
+		return Alarm{}, nil
+	case "morning":
+		return Alarm{Before: -morning}, nil
+	}
+
+	rest, at, hasTime := strings.Cut(s, "@")
+	var timeOfDay time.Duration
+	if hasTime {
+		t, err := time.Parse("15:04", strings.TrimSpace(at))
+		if err != nil {
+			return Alarm{}, invalid
+		}
+		timeOfDay = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		rest = strings.TrimSpace(rest)
+	}
+
+	var before time.Duration
+	if days, after, found := strings.Cut(rest, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return Alarm{}, invalid
+		}
+		before = time.Duration(n) * 24 * time.Hour
+		rest = after
+	}
+	if rest != "" {
+		if hasTime {
+			// A time of day only makes sense a whole number of days before
+			return Alarm{}, invalid
+		}
+		// Only hours and minutes, which calendar apps can show
+		d, err := time.ParseDuration(rest)
+		if err != nil || d < 0 || d%time.Minute != 0 {
+			return Alarm{}, invalid
+		}
+		before += d
+	}
+	return Alarm{Before: before - timeOfDay}, nil
+}
+
+// String formats the alarm the way ParseAlarm reads it, e.g. "1d12h" or
+// "@08:00".
+func (a Alarm) String() string {
+	before := a.Before
+	if before == 0 {
+		return "0"
+	}
+	if before < 0 {
+		// A time of day on the day of an all-day event
+		return fmt.Sprintf("@%02d:%02d", -before/time.Hour, -before%time.Hour/time.Minute)
+	}
+
+	var s string
+	if days := before / (24 * time.Hour); days > 0 {
+		s = fmt.Sprintf("%dd", days)
+		before -= days * 24 * time.Hour
+	}
+	if hours := before / time.Hour; hours > 0 {
+		s += fmt.Sprintf("%dh", hours)
+		before -= hours * time.Hour
+	}
+	if minutes := before / time.Minute; minutes > 0 {
+		s += fmt.Sprintf("%dm", minutes)
+		before -= minutes * time.Minute
+	}
+	if before > 0 {
+		s += fmt.Sprintf("%ds", before/time.Second)
+	}
+	return s
+}
+
+// WithCategoryAlarms sets the alarms of the events of a category that have
+// none of their own. An empty category sets the alarms of every other event
+// without alarms.
+func WithCategoryAlarms(category string, alarms ...Alarm) Option {
+	return func(g *Generator) {
+		if g.categoryAlarms == nil {
+			g.categoryAlarms = make(map[string][]Alarm)
+		}
+		g.categoryAlarms[category] = append(g.categoryAlarms[category], alarms...)
+	}
+}
+
+// ParseCategoryAlarm parses an alarm for the events of a category, written
+// as category=alarm, e.g. "Giỗ=3d@08:00", or as a bare alarm for every
+// event.
+func ParseCategoryAlarm(s string) (category string, alarm Alarm, err error) {
+	spec := s
+	if c, a, found := strings.Cut(s, "="); found {
+		category, spec = strings.TrimSpace(c), a
+	}
+	alarm, err = ParseAlarm(spec)
+	return category, alarm, err
+}
+
+// applyCategoryAlarms gives the event the alarms of its category, or the
+// default ones, unless it has its own.
+func (g *Generator) applyCategoryAlarms(e Event) Event {
+	if len(e.Alarms) > 0 || len(g.categoryAlarms) == 0 {
+		return e
+	}
+	if alarms, ok := g.categoryAlarms[e.Category]; ok {
+		e.Alarms = alarms
+		return e
+	}
+	e.Alarms = g.categoryAlarms[""]
+	return e
+}
@@ -0,0 +1,82 @@
+package calendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlarm(t *testing.T) {
+	for input, expected := range map[string]time.Duration{
+		"0":        0,
+		"1d":       24 * time.Hour,
+		"2h":       2 * time.Hour,
+		"30m":      30 * time.Minute,
+		"1d12h":    36 * time.Hour,
+		" 2h30m":   2*time.Hour + 30*time.Minute,
+		"3d@08:00": 3*24*time.Hour - 8*time.Hour,
+		"@07:30":   -7*time.Hour - 30*time.Minute,
+		"morning":  -8 * time.Hour,
+	} {
+		alarm, err := calendar.ParseAlarm(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, alarm.Before, input)
+	}
+
+	for _, invalid := range []string{"", "d", "1x", "-1d", "-2h", "1d2", "1d@8", "2h@08:00", "1d@25:00", "1ms", "500us", "30s", "1d30s"} {
+		_, err := calendar.ParseAlarm(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestAlarm_String(t *testing.T) {
+	for expected, before := range map[string]time.Duration{
+		"0":      0,
+		"1d":     24 * time.Hour,
+		"2h30m":  2*time.Hour + 30*time.Minute,
+		"2d16h":  3*24*time.Hour - 8*time.Hour,
+		"@07:30": -7*time.Hour - 30*time.Minute,
+	} {
+		s := calendar.Alarm{Before: before}.String()
+		require.Equal(t, expected, s)
+
+		alarm, err := calendar.ParseAlarm(s)
+		require.NoError(t, err)
+		require.Equal(t, before, alarm.Before)
+	}
+}
+
+func TestParseCategoryAlarm(t *testing.T) {
+	category, alarm, err := calendar.ParseCategoryAlarm("Giỗ=3d@08:00")
+	require.NoError(t, err)
+	require.Equal(t, "Giỗ", category)
+	require.Equal(t, 64*time.Hour, alarm.Before)
+
+	category, alarm, err = calendar.ParseCategoryAlarm("1d")
+	require.NoError(t, err)
+	require.Empty(t, category)
+	require.Equal(t, 24*time.Hour, alarm.Before)
+
+	_, _, err = calendar.ParseCategoryAlarm("Giỗ=soon")
+	require.Error(t, err)
+}
+
+func TestGenerator_WithCategoryAlarms(t *testing.T) {
+	day := calendar.Alarm{Before: 24 * time.Hour}
+	morning := calendar.Alarm{Before: -8 * time.Hour}
+	gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi",
+		calendar.WithFestivals(calendar.FestivalTet),
+		calendar.WithAnniversaries(calendar.Anniversary{Name: "Ông", LunarDeath: calendar.LunarDate{Year: 2020, Day: 2, Month: 11}}),
+		calendar.WithRules(calendar.Rule{Title: "Own", Lunar: calendar.LunarDate{Day: 5, Month: 5}, Recurring: true, Alarms: []calendar.Alarm{{}}}),
+		calendar.WithCategoryAlarms(calendar.CategoryAnniversary, day, morning),
+		calendar.WithCategoryAlarms("", morning),
+	)
+	events, err := gen.Generate("")
+
+	require.NoError(t, err)
+	require.Equal(t, []calendar.Alarm{morning}, findEventByTitle(events, "Tết Nguyên Đán").Alarms)
+	require.Equal(t, []calendar.Alarm{day, morning}, findEventByTitle(events, "Giỗ Ông (6 năm)").Alarms)
+	require.Equal(t, []calendar.Alarm{{}}, findEventByTitle(events, "Own").Alarms)
+}
@@ -0,0 +1,230 @@
+package calendar
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
+)
+
+// resolveAnchors replaces the RelativeTo anchor of each rule with the anchor
+// of the event it names, adding up the offsets along the way. A name is
+// looked up among the rule titles, then the built-in festival IDs and
+// titles, then the solar terms.
+func resolveAnchors(rules []Rule) ([]Rule, error) {
+	byTitle := make(map[string]int, len(rules))
+	for i, r := range rules {
+		if _, ok := byTitle[r.Title]; ok {
+			byTitle[r.Title] = -1
+			continue
+		}
+		byTitle[r.Title] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	resolved := make([]Rule, len(rules))
+	state := make([]int, len(rules))
+
+	var resolve func(i int) error
+	resolve = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("%q is anchored on itself", rules[i].Title)
+		case done:
+			return nil
+		}
+
+		r := rules[i]
+		if r.RelativeTo == "" {
+			resolved[i], state[i] = r, done
+			return nil
+		}
+		state[i] = visiting
+
+		var base Rule
+		name := r.RelativeTo
+		if j, ok := byTitle[name]; ok {
+			if j < 0 {
+				return fmt.Errorf("invalid anchor %q, several events have that title", name)
+			}
+			if err := resolve(j); err != nil {
+				return err
+			}
+			base = resolved[j]
+		} else if f, ok := festivalNamed(name); ok {
+			if f.Monthly {
+				return fmt.Errorf("invalid anchor %q, the festival is held every month", name)
+			}
+			base = Rule{Lunar: LunarDate{Day: f.Date.Day, Month: f.Date.Month, Leap: f.Date.Leap}, LastDay: f.LastDay, Offset: f.Offset, Recurring: true}
+			name = f.Title
+		} else if isSolarTerm(name) {
+			base = Rule{SolarTerm: name, Recurring: true}
+			name = "tiết " + name
+		} else {
+			return fmt.Errorf("invalid anchor %q, expected the title of another event, a festival or a solar term", name)
+		}
+
+		out := r
+		out.Lunar, out.LastDay, out.Solar, out.SolarTerm = base.Lunar, base.LastDay, base.Solar, base.SolarTerm
+		out.Recurring = base.Recurring
+		out.Offset = base.Offset + r.Offset
+		if out.MissingDate == nil {
+			out.MissingDate = base.MissingDate
+		}
+		out.RelativeTo = ""
+		out.anchor = withOffset(r.Offset, name)
+		if r.Offset == 0 && base.SolarTerm == "" {
+			out.anchor = "cùng ngày " + name
+		}
+		resolved[i], state[i] = out, done
+		return nil
+	}
+
+	for i, r := range rules {
+		if err := resolve(i); err != nil {
+			if r.Source != "" {
+				return nil, fmt.Errorf("%s: %w", r.Source, err)
+			}
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// festivalNamed returns the built-in festival with the ID or title name.
+func festivalNamed(name string) (Festival, bool) {
+	for _, f := range Festivals {
+		if f.ID == name || f.Title == name {
+			return f, true
+		}
+	}
+	return Festival{}, false
+}
+
+func isSolarTerm(name string) bool {
+	return slices.Contains(lunar.SolarTermNames(), name)
+}
+
+// solarTermRuleEvents returns the occurrences of a rule anchored on a solar
+// term, one every Gregorian year.
+func (g *Generator) solarTermRuleEvents(r Rule) ([]Event, error) {
+	if !isSolarTerm(r.SolarTerm) {
+		return nil, fmt.Errorf("unknown solar term %q, expected one of %s", r.SolarTerm, strings.Join(lunar.SolarTermNames(), ", "))
+	}
+
+	loc := g.location()
+	description := r.Description
+	if description == "" {
+		description = r.Title + " - " + capitalize(describeAnchor(r))
+	}
+
+	var events []Event
+	extra := offsetYears(r.Offset)
+	for year := g.startYear - extra; year < g.startYear+g.yearsAhead+extra; year++ {
+		for _, term := range lunar.SolarTerms(year) {
+			if term.Name != r.SolarTerm {
+				continue
+			}
+			local := term.Time.In(loc)
+			date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, r.Offset)
+			if !g.inSpan(date) {
+				continue
+			}
+			lunarYear, ld := lunar.FromSolar(date, lunar.WithTimezone(g.timezone))
+			events = append(events, Event{
+				ID:          r.ID,
+				Title:       r.Title,
+				Date:        date,
+				LunarDate:   LunarDate{Year: lunarYear, Day: ld.Day, Month: ld.Month, Leap: ld.Leap, Show: false},
+				Description: description,
+				Category:    r.Category,
+				Alarms:      r.Alarms,
+				Days:        r.Days,
+			})
+		}
+	}
+	return events, nil
+}
+
+// shift moves the event by days, updating its lunar date.
+func (g *Generator) shift(event Event, days int) Event {
+	if days == 0 {
+		return event
+	}
+	event.Date = event.Date.AddDate(0, 0, days)
+	year, ld := lunar.FromSolar(event.Date, lunar.WithTimezone(g.timezone))
+	event.LunarDate = LunarDate{Year: year, Day: ld.Day, Month: ld.Month, Leap: ld.Leap, Show: event.LunarDate.Show}
+	return event
+}
+
+// offsetYears returns how many years an offset can move an occurrence
+// across, rounding up.
+func offsetYears(offset int) int {
+	if offset < 0 {
+		offset = -offset
+	}
+	if offset == 0 {
+		return 0
+	}
+	// A lunar year has at least 353 days
+	return offset/353 + 1
+}
+
+// describeAnchor describes when a rule happens, for its default description,
+// e.g. "ngày cuối tháng 12 âm lịch" or "49 ngày sau ngày 10 tháng 3 năm 2025
+// âm lịch".
+func describeAnchor(r Rule) string {
+	if r.anchor != "" {
+		return r.anchor
+	}
+
+	var s string
+	d := r.Lunar
+	switch {
+	case r.SolarTerm != "":
+		s = "tiết " + r.SolarTerm
+	case !r.Solar.IsZero():
+		s = fmt.Sprintf("ngày %d tháng %d dương lịch", r.Solar.Day(), r.Solar.Month())
+	case r.LastDay:
+		s = "ngày cuối tháng " + monthName(d.Month, d.Leap)
+	default:
+		s = fmt.Sprintf("ngày %d tháng %s", d.Day, monthName(d.Month, d.Leap))
+	}
+	if r.SolarTerm == "" && r.Solar.IsZero() {
+		if !r.Recurring {
+			s += fmt.Sprintf(" năm %d", d.Year)
+		}
+		s += " âm lịch"
+	}
+	return withOffset(r.Offset, s)
+}
+
+// withOffset describes the day offset days away from anchor.
+func withOffset(offset int, anchor string) string {
+	switch {
+	case offset < 0:
+		return fmt.Sprintf("%d ngày trước %s", -offset, anchor)
+	case offset > 0:
+		return fmt.Sprintf("%d ngày sau %s", offset, anchor)
+	}
+	return anchor
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// formatLastDay formats the date of a rule on the last day of a month, e.g.
+// "last/12".
+func formatLastDay(ld LunarDate) string {
+	return "last" + strings.TrimPrefix(formatLunarDate(LunarDate{Year: ld.Year, Day: 0, Month: ld.Month, Leap: ld.Leap}), "0")
+}
@@ -0,0 +1,105 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
+)
+
+// Anniversary is a giỗ, the yearly commemoration of a death on the lunar
+// date of the death. Either SolarDeath or LunarDeath is set; a solar date is
+// converted in the generator's timezone.
+type Anniversary struct {
+	Name       string
+	SolarDeath time.Time
+	LunarDeath LunarDate
+}
+
+// WithAnniversaries adds the giỗ of each anniversary. A death in a leap
+// month is commemorated in the regular month of the same number, and a death
+// on day 30 is commemorated on day 29 in years whose month is short. The
+// first anniversary is giỗ đầu and the second, in the third year of
+// mourning, is giỗ hết (đại tường).
+func WithAnniversaries(anniversaries ...Anniversary) Option {
+	return func(g *Generator) {
+		g.anniversaries = append(g.anniversaries, anniversaries...)
+	}
+}
+
+// ParseAnniversaries parses comma separated anniversaries in the format
+// date:name, where date is the solar date of death as yyyy-mm-dd or the
+// lunar date of death as day/month/year, e.g.
+// `2020-03-15:Ông Nội,10/3n/2023:"Bà Ngoại, Huệ"`. Names are quoted or
+// escaped like the titles of ParseEvents.
+func ParseAnniversaries(s string) ([]Anniversary, error) {
+	anniversaries, err := parseItems(s, (*parser).anniversary, "anniversaries")
+	if err != nil {
+		return nil, fmt.Errorf("invalid anniversary: %w", err)
+	}
+	return anniversaries, nil
+}
+
+// lunarDeath returns the lunar date of death, checking that it exists.
+func (g *Generator) lunarDeath(a Anniversary) (LunarDate, error) {
+	if !a.SolarDeath.IsZero() {
+		s := a.SolarDeath
+		year, ld := lunar.FromSolar(time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, g.location()), lunar.WithTimezone(g.timezone))
+		return LunarDate{Year: year, Day: ld.Day, Month: ld.Month, Leap: ld.Leap}, nil
+	}
+
+	death := a.LunarDeath
+	if _, err := g.resolveLunarDate(death.Year, lunar.Date{Day: death.Day, Month: death.Month, Leap: death.Leap}); err != nil {
+		return LunarDate{}, err
+	}
+	return death, nil
+}
+
+func (g *Generator) generateAnniversaries() ([]Event, error) {
+	var events []Event
+
+	for _, a := range g.anniversaries {
+		death, err := g.lunarDeath(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid anniversary of %s: %w", a.Name, err)
+		}
+
+		// The giỗ is held in the regular month even when the death was in
+		// a leap month
+		ld := lunar.Date{Day: death.Day, Month: death.Month}
+		first, last := g.lunarYears()
+		for year := max(first, death.Year+1); year <= last; year++ {
+			count := year - death.Year
+			title, kind := anniversaryTitle(a.Name, count)
+			description := fmt.Sprintf("%s - %s, %d năm sau ngày mất %s âm lịch", title, kind, count, formatLunarDate(death))
+
+			template := Event{ID: "anniversary/" + formatLunarDate(death) + "/" + a.Name, Title: title, Description: description, Category: CategoryAnniversary}
+			event, moved, err := g.customOccurrence(template, year, ld, MissingDateLastDay)
+			if err != nil {
+				return nil, fmt.Errorf("invalid anniversary of %s: %w", a.Name, err)
+			}
+			if !g.inSpan(event.Date) {
+				continue
+			}
+			if moved != nil {
+				g.warn(title, moved, "moved to "+formatLunarDate(event.LunarDate))
+			}
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// anniversaryTitle returns the title of the count-th giỗ of name, and the
+// name of the rite for the description.
+func anniversaryTitle(name string, count int) (title, kind string) {
+	switch count {
+	case 1:
+		return "Giỗ đầu " + name, "tiểu tường"
+	case 2:
+		return "Giỗ hết " + name, "đại tường, năm thứ ba để tang"
+	default:
+		return fmt.Sprintf("Giỗ %s (%d năm)", name, count), "giỗ thường"
+	}
+}
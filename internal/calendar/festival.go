@@ -0,0 +1,304 @@
+package calendar
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
+)
+
+// Festival IDs identify the built-in festivals, to include or exclude them.
+const (
+	FestivalTet          = "tet"
+	FestivalThuongNguyen = "thuong-nguyen"
+	FestivalHungKings    = "hung-kings"
+	FestivalDoanNgo      = "doan-ngo"
+	FestivalVuLan        = "vu-lan"
+	FestivalTrungThu     = "trung-thu"
+	// FestivalMung1 is the first day of every lunar month.
+	FestivalMung1 = "mung-1"
+	// FestivalRam is the 15th day of every lunar month. It belongs to no
+	// pack; WithFullMoonDays enables it.
+	FestivalRam = "ram"
+)
+
+// Packs group the built-in festivals. The core pack is always generated,
+// the others on request.
+const (
+	PackCore      = "core"
+	PackTetPeriod = "tet-period"
+	PackBuddhist  = "buddhist"
+	PackFolk      = "folk"
+)
+
+// Packs lists the festival packs.
+var Packs = []string{PackCore, PackTetPeriod, PackBuddhist, PackFolk}
+
+// Festival is a built-in festival held every lunar year.
+type Festival struct {
+	ID    string
+	Pack  string
+	Title string
+	Date  lunar.Date
+	// LastDay places the festival on the last day of Date.Month, the 29th
+	// or the 30th.
+	LastDay bool
+	// Offset moves the festival by a number of days from Date, e.g. -1 for
+	// Tất Niên, the day before Giao Thừa.
+	Offset int
+	// Monthly festivals are held on Date.Day of every month, leap months
+	// included, except where another event already falls on that day. Their
+	// title and descriptions are formatted with the month, e.g. "6" or
+	// "6 nhuận".
+	Monthly bool
+	// Description is in Vietnamese, DescriptionEN in English.
+	Description   string
+	DescriptionEN string
+}
+
+// Festivals is the catalog of built-in festivals, in the order they are
+// generated within a lunar year.
+var Festivals = []Festival{
+	{
+		ID: FestivalTet, Pack: PackCore, Title: "Tết Nguyên Đán", Date: lunar.Tet,
+		Description:   "Mùng 1 Tết, năm mới âm lịch",
+		DescriptionEN: "Vietnamese Lunar New Year",
+	},
+	{
+		ID: FestivalThuongNguyen, Pack: PackCore, Title: "Tết Thượng Nguyên", Date: lunar.Date{Day: 15, Month: 1},
+		Description:   "Rằm tháng Giêng, rằm đầu tiên của năm",
+		DescriptionEN: "Lantern Festival, the first full moon of the year",
+	},
+	{
+		ID: FestivalHungKings, Pack: PackCore, Title: "Giỗ Tổ Hùng Vương", Date: lunar.HungKingCommemoration,
+		Description:   "Mùng 10 tháng 3, giỗ các Vua Hùng",
+		DescriptionEN: "Hung Kings' Commemoration",
+	},
+	{
+		ID: FestivalDoanNgo, Pack: PackCore, Title: "Tết Đoan Ngọ", Date: lunar.DuongNgoc,
+		Description:   "Mùng 5 tháng 5, Tết diệt sâu bọ",
+		DescriptionEN: "Double Fifth Festival",
+	},
+	{
+		ID: FestivalVuLan, Pack: PackCore, Title: "Vu Lan", Date: lunar.VuLan,
+		Description:   "Rằm tháng 7, lễ báo hiếu cha mẹ và xá tội vong nhân",
+		DescriptionEN: "Ghost Festival and day of filial piety",
+	},
+	{
+		ID: FestivalTrungThu, Pack: PackCore, Title: "Tết Trung Thu", Date: lunar.TrungThu,
+		Description:   "Rằm tháng 8, Tết thiếu nhi",
+		DescriptionEN: "Mid-Autumn Festival",
+	},
+
+	{
+		ID: "mung-2", Pack: PackTetPeriod, Title: "Mùng 2 Tết", Date: lunar.Date{Day: 2, Month: 1},
+		Description:   "Mùng 2 Tết, chúc Tết bên ngoại",
+		DescriptionEN: "Second day of Tết, visiting the mother's family",
+	},
+	{
+		ID: "mung-3", Pack: PackTetPeriod, Title: "Mùng 3 Tết", Date: lunar.Date{Day: 3, Month: 1},
+		Description:   "Mùng 3 Tết, chúc Tết thầy cô",
+		DescriptionEN: "Third day of Tết, visiting teachers",
+	},
+	{
+		ID: "ong-cong-ong-tao", Pack: PackTetPeriod, Title: "Ông Công Ông Táo", Date: lunar.Date{Day: 23, Month: 12},
+		Description:   "23 tháng Chạp, tiễn Táo Quân về trời",
+		DescriptionEN: "Kitchen Gods' Day, seeing the Kitchen Gods off to heaven",
+	},
+	{
+		ID: "tat-nien", Pack: PackTetPeriod, Title: "Tất Niên", Date: lunar.Date{Month: 12}, LastDay: true, Offset: -1,
+		Description:   "Ngày trước Giao Thừa, bữa cơm tất niên",
+		DescriptionEN: "The day before Lunar New Year's Eve, the year-end family meal",
+	},
+	{
+		ID: "giao-thua", Pack: PackTetPeriod, Title: "Giao Thừa", Date: lunar.Date{Month: 12}, LastDay: true,
+		Description:   "Đêm chuyển giao năm cũ và năm mới",
+		DescriptionEN: "Lunar New Year's Eve",
+	},
+
+	{
+		ID: "via-than-tai", Pack: PackBuddhist, Title: "Vía Thần Tài", Date: lunar.Date{Day: 10, Month: 1},
+		Description:   "Mùng 10 tháng Giêng, ngày vía Thần Tài",
+		DescriptionEN: "God of Wealth Day",
+	},
+	{
+		ID: "quan-am-dan-sanh", Pack: PackBuddhist, Title: "Vía Quan Âm Đản Sanh", Date: lunar.Date{Day: 19, Month: 2},
+		Description:   "19 tháng 2, ngày Bồ Tát Quán Thế Âm đản sanh",
+		DescriptionEN: "Birth of Guanyin Bodhisattva",
+	},
+	{
+		ID: "phat-dan", Pack: PackBuddhist, Title: "Lễ Phật Đản", Date: lunar.Date{Day: 15, Month: 4},
+		Description:   "Rằm tháng 4, ngày Đức Phật đản sinh",
+		DescriptionEN: "Vesak, the Buddha's birthday",
+	},
+	{
+		ID: "quan-am-thanh-dao", Pack: PackBuddhist, Title: "Vía Quan Âm Thành Đạo", Date: lunar.Date{Day: 19, Month: 6},
+		Description:   "19 tháng 6, ngày Bồ Tát Quán Thế Âm thành đạo",
+		DescriptionEN: "Enlightenment of Guanyin Bodhisattva",
+	},
+	{
+		ID: "quan-am-xuat-gia", Pack: PackBuddhist, Title: "Vía Quan Âm Xuất Gia", Date: lunar.Date{Day: 19, Month: 9},
+		Description:   "19 tháng 9, ngày Bồ Tát Quán Thế Âm xuất gia",
+		DescriptionEN: "Renunciation of Guanyin Bodhisattva",
+	},
+
+	{
+		ID: "han-thuc", Pack: PackFolk, Title: "Tết Hàn Thực", Date: lunar.Date{Day: 3, Month: 3},
+		Description:   "Mùng 3 tháng 3, Tết bánh trôi bánh chay",
+		DescriptionEN: "Cold Food Festival",
+	},
+	{
+		ID: "song-that", Pack: PackFolk, Title: "Tết Song Thất", Date: lunar.Date{Day: 7, Month: 7},
+		Description:   "Mùng 7 tháng 7, ngày Ngưu Lang Chức Nữ gặp nhau",
+		DescriptionEN: "Double Seventh Festival",
+	},
+	{
+		ID: "trung-cuu", Pack: PackFolk, Title: "Tết Trùng Cửu", Date: lunar.Date{Day: 9, Month: 9},
+		Description:   "Mùng 9 tháng 9, Tết Trùng Dương",
+		DescriptionEN: "Double Ninth Festival",
+	},
+	{
+		ID: "ha-nguyen", Pack: PackFolk, Title: "Tết Hạ Nguyên", Date: lunar.Date{Day: 15, Month: 10},
+		Description:   "Rằm tháng 10, Tết cơm mới",
+		DescriptionEN: "Lower Yuan Festival, the new rice festival",
+	},
+
+	{
+		ID: FestivalMung1, Pack: PackCore, Title: "Mùng 1 Tháng %s (Âm lịch)", Date: lunar.Date{Day: 1}, Monthly: true,
+		Description:   "Mùng 1 tháng %s âm lịch",
+		DescriptionEN: "First day of lunar month %s",
+	},
+	{
+		ID: FestivalRam, Title: "Rằm Tháng %s (Âm lịch)", Date: lunar.Date{Day: 15}, Monthly: true,
+		Description:   "Rằm tháng %s âm lịch",
+		DescriptionEN: "Full moon day of lunar month %s",
+	},
+}
+
+// FestivalIDs lists the IDs of the built-in festivals in catalog order.
+var FestivalIDs = func() []string {
+	ids := make([]string, len(Festivals))
+	for i, f := range Festivals {
+		ids[i] = f.ID
+	}
+	return ids
+}()
+
+// Languages of the festival descriptions.
+const (
+	Vietnamese = "vi"
+	English    = "en"
+)
+
+// WithFestivals generates only the built-in festivals with the given IDs,
+// whatever their pack.
+func WithFestivals(ids ...string) Option {
+	return func(g *Generator) {
+		g.includeFestivals = append(g.includeFestivals, ids...)
+	}
+}
+
+// WithoutFestivals leaves out the built-in festivals with the given IDs.
+func WithoutFestivals(ids ...string) Option {
+	return func(g *Generator) {
+		g.excludeFestivals = append(g.excludeFestivals, ids...)
+	}
+}
+
+// WithFestivalPacks adds the festivals of the given packs to the core pack.
+func WithFestivalPacks(packs ...string) Option {
+	return func(g *Generator) {
+		g.packs = append(g.packs, packs...)
+	}
+}
+
+// WithLanguage sets the language of the festival descriptions, Vietnamese
+// (the default) or English.
+func WithLanguage(lang string) Option {
+	return func(g *Generator) {
+		g.language = lang
+	}
+}
+
+// ParseFestivalIDs parses a comma separated list of festival IDs.
+func ParseFestivalIDs(s string) ([]string, error) {
+	return parseList(s, FestivalIDs, unknownFestivalError)
+}
+
+// ParseFestivalPacks parses a comma separated list of festival packs.
+func ParseFestivalPacks(s string) ([]string, error) {
+	return parseList(s, Packs, unknownPackError)
+}
+
+func parseList(s string, known []string, unknown func(string) error) ([]string, error) {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !slices.Contains(known, v) {
+			return nil, unknown(v)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func unknownFestivalError(id string) error {
+	return fmt.Errorf("unknown festival %q, expected one of %s", id, strings.Join(FestivalIDs, ", "))
+}
+
+func unknownPackError(pack string) error {
+	return fmt.Errorf("unknown festival pack %q, expected one of %s", pack, strings.Join(Packs, ", "))
+}
+
+// festivals returns whether each built-in festival is generated.
+func (g *Generator) festivals() (map[string]bool, error) {
+	packs := map[string]bool{PackCore: true}
+	for _, pack := range g.packs {
+		if !slices.Contains(Packs, pack) {
+			return nil, unknownPackError(pack)
+		}
+		packs[pack] = true
+	}
+
+	enabled := make(map[string]bool, len(Festivals))
+	for _, f := range Festivals {
+		enabled[f.ID] = len(g.includeFestivals) == 0 && packs[f.Pack]
+	}
+	if g.fullMoonDays {
+		enabled[FestivalRam] = true
+	}
+	for _, id := range g.includeFestivals {
+		if _, ok := enabled[id]; !ok {
+			return nil, unknownFestivalError(id)
+		}
+		enabled[id] = true
+	}
+	for _, id := range g.excludeFestivals {
+		if _, ok := enabled[id]; !ok {
+			return nil, unknownFestivalError(id)
+		}
+		enabled[id] = false
+	}
+	return enabled, nil
+}
+
+// monthLabel names the month in the descriptions of monthly festivals, e.g.
+// "6 nhuận" or, in English, "6 (leap)".
+func (g *Generator) monthLabel(month int, leap bool) string {
+	if leap && g.language == English {
+		return fmt.Sprintf("%d (leap)", month)
+	}
+	return monthName(month, leap)
+}
+
+// description returns the description of f in the generator's language.
+func (g *Generator) description(f Festival) string {
+	if g.language == English {
+		return f.DescriptionEN
+	}
+	return f.Description
+}
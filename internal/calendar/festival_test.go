@@ -0,0 +1,202 @@
+package calendar_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/stretchr/testify/require"
+)
+
+func countTitlePrefix(events []calendar.Event, prefix string) int {
+	count := 0
+	for _, e := range events {
+		if strings.HasPrefix(e.Title, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestGenerator_Festivals(t *testing.T) {
+	t.Run("custom events are added to the default festivals", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+		events, err := gen.Generate("12/3:Sinh nhật mẹ")
+
+		require.NoError(t, err)
+		require.NotNil(t, findEventByTitle(events, "Tết Nguyên Đán"))
+		require.NotNil(t, findEventByTitle(events, "Tết Trung Thu"))
+		require.NotNil(t, findEventByTitle(events, "Sinh nhật mẹ"))
+	})
+
+	t.Run("custom event on day 1 replaces the Mùng 1 entry of its month", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+		events, err := gen.Generate("1/3:Đi chùa")
+
+		require.NoError(t, err)
+		require.NotNil(t, findEventByTitle(events, "Đi chùa"))
+		require.Nil(t, findEventByTitle(events, "Mùng 1 Tháng 3 (Âm lịch)"))
+		require.NotNil(t, findEventByTitle(events, "Mùng 1 Tháng 4 (Âm lịch)"))
+	})
+
+	t.Run("custom event on day 1 of one year only keeps Mùng 1 in other years", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi")
+		events, err := gen.Generate("1/3/2026:Đi chùa")
+
+		require.NoError(t, err)
+		mung1 := findEventByTitle(events, "Mùng 1 Tháng 3 (Âm lịch)")
+		require.NotNil(t, mung1)
+		require.Equal(t, 2027, mung1.LunarDate.Year)
+	})
+
+	t.Run("custom event on day 1 of a leap month keeps Mùng 1 of the regular month", func(t *testing.T) {
+		gen := calendar.NewGenerator(2025, 1, "Asia/Hanoi")
+		events, err := gen.Generate("1/6n:Đi chùa")
+
+		require.NoError(t, err)
+		require.NotNil(t, findEventByTitle(events, "Mùng 1 Tháng 6 (Âm lịch)"))
+		require.Nil(t, findEventByTitle(events, "Mùng 1 Tháng 6 nhuận (Âm lịch)"))
+	})
+
+	t.Run("monthly festivals are held in the leap month too", func(t *testing.T) {
+		gen := calendar.NewGenerator(2025, 1, "Asia/Hanoi", calendar.WithFestivals(calendar.FestivalMung1, calendar.FestivalRam), calendar.WithLanguage(calendar.English))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		mung1 := findEventByTitle(events, "Mùng 1 Tháng 6 nhuận (Âm lịch)")
+		require.NotNil(t, mung1)
+		require.Equal(t, "2025-07-25", mung1.Date.Format(time.DateOnly))
+		require.Equal(t, calendar.LunarDate{Year: 2025, Day: 1, Month: 6, Leap: true}, mung1.LunarDate)
+		require.Equal(t, "First day of lunar month 6 (leap)", mung1.Description)
+		require.NotNil(t, findEventByTitle(events, "Rằm Tháng 6 nhuận (Âm lịch)"))
+	})
+
+	t.Run("Tất Niên is the day before Giao Thừa", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi", calendar.WithFestivals("tat-nien", "giao-thua"))
+		events, err := gen.Generate("{tat-nien}:Dọn nhà")
+
+		require.NoError(t, err)
+		for _, year := range []int{2025, 2026} {
+			var tatNien, giaoThua, custom *calendar.Event
+			for i, e := range events {
+				if e.LunarDate.Year != year {
+					continue
+				}
+				switch e.Title {
+				case "Tất Niên":
+					tatNien = &events[i]
+				case "Giao Thừa":
+					giaoThua = &events[i]
+				case "Dọn nhà":
+					custom = &events[i]
+				}
+			}
+			require.NotNil(t, tatNien, year)
+			require.NotNil(t, giaoThua, year)
+			require.Equal(t, giaoThua.Date.AddDate(0, 0, -1), tatNien.Date)
+			require.Equal(t, giaoThua.LunarDate.Day-1, tatNien.LunarDate.Day)
+			require.Equal(t, tatNien.Date, custom.Date)
+		}
+	})
+
+	t.Run("excludes festivals by ID", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithoutFestivals(calendar.FestivalMung1, calendar.FestivalVuLan))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.NotNil(t, findEventByTitle(events, "Tết Nguyên Đán"))
+		require.Nil(t, findEventByTitle(events, "Vu Lan"))
+		require.Zero(t, countTitlePrefix(events, "Mùng 1"))
+	})
+
+	t.Run("includes only the given festivals", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithFestivals(calendar.FestivalTet, calendar.FestivalTrungThu))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, "Tết Nguyên Đán", events[0].Title)
+		require.Equal(t, "Tết Trung Thu", events[1].Title)
+	})
+
+	t.Run("unknown festival ID returns error", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithoutFestivals("christmas"))
+		_, err := gen.Generate("")
+
+		require.ErrorContains(t, err, `unknown festival "christmas"`)
+	})
+}
+
+func TestGenerator_FestivalPacks(t *testing.T) {
+	t.Run("packs add their festivals to the core festivals", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithFestivalPacks(calendar.PackBuddhist, calendar.PackFolk))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.NotNil(t, findEventByTitle(events, "Tết Nguyên Đán"))
+		phatDan := findEventByTitle(events, "Lễ Phật Đản")
+		require.NotNil(t, phatDan)
+		require.Equal(t, "2026-05-31", phatDan.Date.Format("2006-01-02"))
+		require.NotNil(t, findEventByTitle(events, "Tết Hàn Thực"))
+		require.Nil(t, findEventByTitle(events, "Ông Công Ông Táo"))
+	})
+
+	t.Run("tet period ends on the last day of month 12", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithFestivalPacks(calendar.PackTetPeriod))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		ongTao := findEventByTitle(events, "Ông Công Ông Táo")
+		require.NotNil(t, ongTao)
+		require.Equal(t, "2026-02-10", ongTao.Date.Format("2006-01-02"))
+		giaoThua := findEventByTitle(events, "Giao Thừa")
+		require.NotNil(t, giaoThua)
+		require.Equal(t, "2026-02-16", giaoThua.Date.Format("2006-01-02"))
+		require.Equal(t, 29, giaoThua.LunarDate.Day)
+	})
+
+	t.Run("festival on Rằm replaces the Rằm entry of its month", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithFestivalPacks(calendar.PackBuddhist), calendar.WithFullMoonDays())
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Nil(t, findEventByTitle(events, "Rằm Tháng 4 (Âm lịch)"))
+		require.NotNil(t, findEventByTitle(events, "Rằm Tháng 5 (Âm lịch)"))
+	})
+
+	t.Run("describes festivals in English", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithFestivals(calendar.FestivalTrungThu), calendar.WithLanguage(calendar.English))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Equal(t, "Tết Trung Thu - Mid-Autumn Festival", events[0].Description)
+	})
+
+	t.Run("unknown pack returns error", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithFestivalPacks("western"))
+		_, err := gen.Generate("")
+
+		require.ErrorContains(t, err, `unknown festival pack "western"`)
+	})
+}
+
+func TestParseFestivalIDs(t *testing.T) {
+	ids, err := calendar.ParseFestivalIDs("tet, mung-1,")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{calendar.FestivalTet, calendar.FestivalMung1}, ids)
+
+	_, err = calendar.ParseFestivalIDs("tet,christmas")
+	require.Error(t, err)
+}
+
+func TestParseFestivalPacks(t *testing.T) {
+	packs, err := calendar.ParseFestivalPacks("buddhist, folk")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{calendar.PackBuddhist, calendar.PackFolk}, packs)
+
+	_, err = calendar.ParseFestivalPacks("western")
+	require.Error(t, err)
+}
@@ -3,209 +3,422 @@ package calendar
 import (
 	"errors"
 	"fmt"
-	"strings"
+	"iter"
+	"slices"
 	"time"
 
 	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
 )
 
 type LunarDate struct {
+	Year  int
 	Day   int
 	Month int
+	Leap  bool
 	Show  bool
 }
 
 type Event struct {
+	// ID identifies the festival, rule or anniversary the event is an
+	// occurrence of. It does not change with the title, so that with the
+	// date it identifies the event across calendar updates.
+	ID          string
 	Title       string
 	Date        time.Time
 	LunarDate   LunarDate
 	Description string
+	Category    string
+	Alarms      []Alarm
+	// Timed events happen at the exact instant in Date rather than lasting
+	// the whole day.
+	Timed bool
+	// Days is how many days an all-day event lasts from Date; 0 and 1 both
+	// mean a single day.
+	Days int
 }
 
+// Categories of the generated events, for CATEGORIES in the ICS file and
+// for WithCategoryAlarms.
+const (
+	CategoryFestival    = "Lễ Tết"
+	CategoryLunarMonth  = "Mùng 1 - Rằm"
+	CategoryAnniversary = "Giỗ"
+	CategorySolarTerm   = "Tiết khí"
+	CategoryMoonPhase   = "Tuần trăng"
+)
+
 type Generator struct {
-	startYear  int
-	yearsAhead int
-	timezone   string
+	startYear    int
+	yearsAhead   int
+	timezone     string
+	solarTerms   bool
+	moonPhases   bool
+	fullMoonDays bool
+	// missingDate is the policy for custom events without their own, see
+	// WithMissingDatePolicy
+	missingDate   MissingDatePolicy
+	anniversaries []Anniversary
+	rules         []Rule
+	warnings      []Warning
+	// includeFestivals and excludeFestivals hold built-in festival IDs
+	includeFestivals []string
+	excludeFestivals []string
+	packs            []string
+	// language of the festival descriptions, Vietnamese unless English
+	language       string
+	categoryAlarms map[string][]Alarm
 }
 
-func NewGenerator(startYear, yearsAhead int, timezone string) *Generator {
+type Option func(*Generator)
+
+// WithSolarTerms adds an event on the day of each of the 24 solar terms
+// (tiết khí), such as Thanh Minh and Đông Chí.
+func WithSolarTerms() Option {
+	return func(g *Generator) {
+		g.solarTerms = true
+	}
+}
+
+// WithMoonPhases adds a timed event at the instant of each new moon (sóc)
+// and full moon (vọng).
+func WithMoonPhases() Option {
+	return func(g *Generator) {
+		g.moonPhases = true
+	}
+}
+
+// WithFullMoonDays adds Rằm, the 15th day, of every lunar month, except
+// where a festival or custom event already falls on it, such as Vu Lan on
+// Rằm tháng 7.
+func WithFullMoonDays() Option {
+	return func(g *Generator) {
+		g.fullMoonDays = true
+	}
+}
+
+func NewGenerator(startYear, yearsAhead int, timezone string, opts ...Option) *Generator {
 	if timezone == "" {
 		timezone = "Asia/Hanoi"
 	}
-	return &Generator{
+	g := &Generator{
 		startYear:  startYear,
 		yearsAhead: yearsAhead,
 		timezone:   timezone,
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
+// Generate returns the events of the calendar, see Events.
 func (g *Generator) Generate(customEvents string) ([]Event, error) {
+	events, err := g.Events(customEvents)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Collect(events), nil
+}
+
+// Events returns the events of the calendar: the built-in festivals, the
+// rules and the custom events, in the -events syntax, and the optional
+// events. Custom events and anniversaries are resolved up front, so that
+// their errors and warnings are known, while the built-in events, which make
+// up most of a long calendar, are produced one year at a time as the
+// sequence is iterated.
+func (g *Generator) Events(customEvents string) (iter.Seq[Event], error) {
+	g.warnings = nil
+
+	rules := g.rules
 	if customEvents != "" {
-		return g.parseCustomEvents(customEvents)
+		custom, err := ParseEvents(customEvents)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(slices.Clip(rules), custom...)
+	}
+
+	rules, err := identifyRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	rules, err = resolveAnchors(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	festivals, err := g.festivals()
+	if err != nil {
+		return nil, err
 	}
-	return g.generateDefaultEvents(), nil
+
+	var custom []Event
+	for _, r := range rules {
+		occurrences, err := g.ruleEvents(r)
+		if err != nil {
+			return nil, err
+		}
+		custom = append(custom, occurrences...)
+	}
+
+	if len(g.anniversaries) > 0 {
+		anniversaries, err := g.generateAnniversaries()
+		if err != nil {
+			return nil, err
+		}
+		custom = append(custom, anniversaries...)
+	}
+
+	return func(yield func(Event) bool) {
+		emit := func(events []Event) bool {
+			for _, e := range events {
+				if !yield(g.applyCategoryAlarms(e)) {
+					return false
+				}
+			}
+			return true
+		}
+
+		first, last := g.lunarYears()
+		for year := first; year <= last; year++ {
+			if !emit(g.defaultEvents(year, festivals, custom)) {
+				return
+			}
+		}
+		if !emit(custom) {
+			return
+		}
+		for year := g.startYear; g.solarTerms && year < g.startYear+g.yearsAhead; year++ {
+			if !emit(g.generateSolarTerms(year)) {
+				return
+			}
+		}
+		for year := g.startYear; g.moonPhases && year < g.startYear+g.yearsAhead; year++ {
+			if !emit(g.generateMoonPhases(year)) {
+				return
+			}
+		}
+	}, nil
 }
 
-func (g *Generator) generateDefaultEvents() []Event {
-	var events []Event
+func (g *Generator) location() *time.Location {
+	if loc, err := lunar.LoadLocation(g.timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// lunarYears returns the lunar years that can have occurrences inside the
+// requested Gregorian span. Months 11 and 12 of a lunar year often fall in
+// January of the next Gregorian year, so the span starts one lunar year early.
+func (g *Generator) lunarYears() (first, last int) {
+	return g.startYear - 1, g.startYear + g.yearsAhead - 1
+}
 
-	for year := g.startYear; year < g.startYear+g.yearsAhead; year++ {
-		events = append(events, g.getEventsForYear(year)...)
+func (g *Generator) inSpan(date time.Time) bool {
+	return !date.IsZero() && date.Year() >= g.startYear && date.Year() < g.startYear+g.yearsAhead
+}
+
+// defaultEvents returns the enabled built-in festivals of the lunar year
+// within the span. A custom event on the first day of a lunar month replaces
+// the generic Mùng 1 entry of that month.
+func (g *Generator) defaultEvents(year int, festivals map[string]bool, custom []Event) []Event {
+	var existing []Event
+	for _, e := range custom {
+		if e.LunarDate.Show && e.LunarDate.Year == year {
+			existing = append(existing, e)
+		}
 	}
 
+	var events []Event
+	for _, e := range g.getEventsForYear(year, festivals, existing) {
+		if g.inSpan(e.Date) {
+			events = append(events, e)
+		}
+	}
 	return events
 }
 
-func (g *Generator) getEventsForYear(year int) []Event {
+// getEventsForYear returns the enabled catalog festivals of the lunar year.
+// Monthly festivals skip the days already taken by a yearly festival or one
+// of the custom events.
+func (g *Generator) getEventsForYear(year int, festivals map[string]bool, custom []Event) []Event {
 	var events []Event
-
 	tzOption := lunar.WithTimezone(g.timezone)
 
-	tetDate := lunar.FindLunarDate(year, lunar.Tet, tzOption)
-	events = append(events, Event{
-		Title:       "Tết Nguyên Đán",
-		Date:        tetDate,
-		LunarDate:   LunarDate{Day: lunar.Tet.Day, Month: lunar.Tet.Month, Show: true},
-		Description: "Tết Nguyên Đán - Vietnamese Lunar New Year",
-	})
-
-	events = append(events, Event{
-		Title:       "Tết Thượng Nguyên",
-		Date:        tetDate.AddDate(0, 0, 14),
-		LunarDate:   LunarDate{Day: 15, Month: 1, Show: true},
-		Description: "Tết Thượng Nguyên - Rằm tháng Giêng",
-	})
-
-	events = append(events, Event{
-		Title:       "Giỗ Tổ Hùng Vương",
-		Date:        lunar.FindLunarDate(year, lunar.HungKingCommemoration, tzOption),
-		LunarDate:   LunarDate{Day: lunar.HungKingCommemoration.Day, Month: lunar.HungKingCommemoration.Month, Show: true},
-		Description: "Giỗ Tổ Hùng Vương",
-	})
-
-	events = append(events, Event{
-		Title:       "Tết Đoan Ngọ",
-		Date:        lunar.FindLunarDate(year, lunar.DuongNgoc, tzOption),
-		LunarDate:   LunarDate{Day: lunar.DuongNgoc.Day, Month: lunar.DuongNgoc.Month, Show: true},
-		Description: "Tết Đoan Ngọ - Mùng 5 tháng 5",
-	})
-
-	vuLan := lunar.FindLunarDate(year, lunar.VuLan, tzOption)
-	events = append(events, Event{
-		Title:       "Vu Lan",
-		Date:        vuLan,
-		LunarDate:   LunarDate{Day: lunar.VuLan.Day, Month: lunar.VuLan.Month, Show: true},
-		Description: "Vu Lan - Rằm tháng 7",
-	})
-
-	trungThu := lunar.FindLunarDate(year, lunar.TrungThu, tzOption)
-	events = append(events, Event{
-		Title:       "Tết Trung Thu",
-		Date:        trungThu,
-		LunarDate:   LunarDate{Day: lunar.TrungThu.Day, Month: lunar.TrungThu.Month, Show: true},
-		Description: "Tết Trung Thu - Rằm tháng 8",
-	})
-
-	events = append(events, g.getFirstDayOfLunarMonths(year, events)...)
+	for _, f := range Festivals {
+		if f.Monthly || !festivals[f.ID] {
+			continue
+		}
+		ld := f.Date
+		if f.LastDay {
+			month, ok := lunar.YearInfo(year, tzOption).Month(ld.Month, ld.Leap)
+			if !ok {
+				continue
+			}
+			ld.Day = month.Days
+		}
+		date := lunar.ToSolar(year, ld, tzOption)
+		if date.IsZero() {
+			continue
+		}
+		events = append(events, g.shift(Event{
+			ID:          "festival/" + f.ID,
+			Title:       f.Title,
+			Date:        date,
+			LunarDate:   LunarDate{Year: year, Day: ld.Day, Month: ld.Month, Leap: ld.Leap, Show: true},
+			Description: f.Title + " - " + g.description(f),
+			Category:    CategoryFestival,
+		}, f.Offset))
+	}
+
+	festivalDays := events
+	for _, f := range Festivals {
+		if f.Monthly && festivals[f.ID] {
+			events = append(events, g.getDayOfLunarMonths(year, f, append(slices.Clip(festivalDays), custom...))...)
+		}
+	}
 
 	return events
 }
 
-func (g *Generator) getFirstDayOfLunarMonths(year int, existingEvents []Event) []Event {
+// getDayOfLunarMonths returns an event of the monthly festival f on its day
+// of each month of the lunar year, leap month included, except for months
+// where one of existingEvents already falls on that day.
+func (g *Generator) getDayOfLunarMonths(year int, f Festival, existingEvents []Event) []Event {
 	var events []Event
-	tzOption := lunar.WithTimezone(g.timezone)
+	day := f.Date.Day
 
 	existingLunarDates := make(map[string]bool)
 	for _, e := range existingEvents {
-		existingLunarDates[fmt.Sprintf("%d/%d", e.LunarDate.Day, e.LunarDate.Month)] = true
+		existingLunarDates[formatLunarDate(LunarDate{Day: e.LunarDate.Day, Month: e.LunarDate.Month, Leap: e.LunarDate.Leap})] = true
 	}
 
-	for month := 1; month <= 12; month++ {
-		lunarDateKey := fmt.Sprintf("1/%d", month)
-		if existingLunarDates[lunarDateKey] {
+	for _, m := range lunar.YearInfo(year, lunar.WithTimezone(g.timezone)).Months {
+		if day > m.Days || existingLunarDates[formatLunarDate(LunarDate{Day: day, Month: m.Month, Leap: m.Leap})] {
 			continue
 		}
-		date := lunar.FindLunarDate(year, lunar.Date{Month: month, Day: 1}, tzOption)
-		if !date.IsZero() {
-			events = append(events, Event{
-				Title:       fmt.Sprintf("Mùng 1 Tháng %d (Âm lịch)", month),
-				Date:        date,
-				LunarDate:   LunarDate{Day: 1, Month: month, Show: false},
-				Description: fmt.Sprintf("Mùng 1 tháng %d âm lịch", month),
-			})
-		}
+		events = append(events, Event{
+			ID:          "festival/" + f.ID,
+			Title:       fmt.Sprintf(f.Title, monthName(m.Month, m.Leap)),
+			Date:        m.Start.AddDate(0, 0, day-1),
+			LunarDate:   LunarDate{Year: year, Day: day, Month: m.Month, Leap: m.Leap, Show: false},
+			Description: fmt.Sprintf(g.description(f), g.monthLabel(m.Month, m.Leap)),
+			Category:    CategoryLunarMonth,
+		})
 	}
 
 	return events
 }
 
-func (g *Generator) parseCustomEvents(eventsStr string) ([]Event, error) {
+// generateSolarTerms returns the solar terms of the Gregorian year.
+func (g *Generator) generateSolarTerms(year int) []Event {
 	var events []Event
+	loc := g.location()
 	tzOption := lunar.WithTimezone(g.timezone)
 
-	parts := strings.Split(eventsStr, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-
-		kv := strings.Split(part, ":")
-		if len(kv) != 2 {
-			return nil, errors.New("invalid format: " + part + ", expected day/month:title or day/month/year:title")
-		}
+	for _, term := range lunar.SolarTerms(year) {
+		local := term.Time.In(loc)
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		lunarYear, ld := lunar.FromSolar(date, tzOption)
+		events = append(events, Event{
+			ID:          "solar-term/" + term.Name,
+			Title:       "Tiết " + term.Name,
+			Date:        date,
+			LunarDate:   LunarDate{Year: lunarYear, Day: ld.Day, Month: ld.Month, Leap: ld.Leap, Show: false},
+			Description: fmt.Sprintf("Tiết %s - Mặt trời ở kinh độ %d° lúc %s", term.Name, term.Longitude, local.Format("15:04 02/01/2006")),
+			Category:    CategorySolarTerm,
+		})
+	}
 
-		datePart := strings.TrimSpace(kv[0])
-		title := strings.TrimSpace(kv[1])
+	return events
+}
 
-		if title == "" {
-			return nil, errors.New("invalid format: " + part + ", title cannot be empty")
+// generateMoonPhases returns the new and full moons of the Gregorian year.
+func (g *Generator) generateMoonPhases(year int) []Event {
+	var events []Event
+	loc := g.location()
+	tzOption := lunar.WithTimezone(g.timezone)
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	to := time.Date(year+1, time.January, 1, 0, 0, 0, 0, loc)
+
+	phases := []struct {
+		id       string
+		title    string
+		name     string
+		instants []time.Time
+	}{
+		{"new-moon", "Trăng non", "Sóc", lunar.NewMoons(from, to)},
+		{"full-moon", "Trăng tròn", "Vọng", lunar.FullMoons(from, to)},
+	}
+	for _, phase := range phases {
+		for _, instant := range phase.instants {
+			local := instant.In(loc)
+			lunarYear, ld := lunar.FromSolar(local, tzOption)
+			events = append(events, Event{
+				ID:          "moon/" + phase.id,
+				Title:       phase.title,
+				Date:        local,
+				LunarDate:   LunarDate{Year: lunarYear, Day: ld.Day, Month: ld.Month, Leap: ld.Leap, Show: false},
+				Description: fmt.Sprintf("%s - %s lúc %s", phase.title, phase.name, local.Format("15:04 02/01/2006")),
+				Category:    CategoryMoonPhase,
+				Timed:       true,
+			})
 		}
+	}
 
-		dateParts := strings.Split(datePart, "/")
-		if len(dateParts) == 2 {
-			var day, month int
-			fmt.Sscanf(dateParts[0], "%d", &day)
-			fmt.Sscanf(dateParts[1], "%d", &month)
-
-			if day == 0 || month == 0 {
-				return nil, errors.New("invalid date: " + datePart + ", day and month must be greater than 0")
-			}
+	return events
+}
 
-			for year := g.startYear; year < g.startYear+g.yearsAhead; year++ {
-				date := lunar.FindLunarDate(year, lunar.Date{Month: month, Day: day}, tzOption)
-				if !date.IsZero() {
-					events = append(events, Event{
-						Title:       title,
-						Date:        date,
-						LunarDate:   LunarDate{Day: day, Month: month, Show: true},
-						Description: fmt.Sprintf("%s - Ngày %d tháng %d âm lịch", title, day, month),
-					})
-				}
-			}
-		} else if len(dateParts) == 3 {
-			var day, month, year int
-			fmt.Sscanf(dateParts[0], "%d", &day)
-			fmt.Sscanf(dateParts[1], "%d", &month)
-			fmt.Sscanf(dateParts[2], "%d", &year)
-
-			if day == 0 || month == 0 {
-				return nil, errors.New("invalid date: " + datePart + ", day and month must be greater than 0")
-			}
+func validateLunarDate(day, month int) error {
+	if day > 30 {
+		return errors.New("lunar months have at most 30 days")
+	}
+	if month > 12 {
+		return errors.New("lunar years have at most 12 months")
+	}
+	return nil
+}
 
-			date := lunar.FindLunarDate(year, lunar.Date{Month: month, Day: day}, tzOption)
-			if !date.IsZero() {
-				events = append(events, Event{
-					Title:       title,
-					Date:        date,
-					LunarDate:   LunarDate{Day: day, Month: month, Show: true},
-					Description: fmt.Sprintf("%s - Ngày %d tháng %d năm %d âm lịch", title, day, month, year),
-				})
-			}
-		} else {
-			return nil, errors.New("invalid date format: " + datePart + ", expected day/month:title or day/month/year:title")
+// customOccurrence returns the occurrence of a custom event in the lunar
+// year, filling in the dates of template. When the month is too short for
+// the day and policy moves the event, moved reports why; when policy does not
+// move it, err is a *shortMonthError and the event carries the date the day
+// would have had.
+func (g *Generator) customOccurrence(template Event, year int, ld lunar.Date, policy MissingDatePolicy) (event Event, moved *shortMonthError, err error) {
+	date, err := g.resolveLunarDate(year, ld)
+	event = template
+	event.Date = date
+	event.LunarDate = LunarDate{Year: year, Day: ld.Day, Month: ld.Month, Leap: ld.Leap, Show: true}
+
+	var short *shortMonthError
+	if errors.As(err, &short) {
+		if date, actual, ok := g.moveMissingDate(short, policy); ok {
+			event.Date = date
+			event.LunarDate = actual
+			event.Description += fmt.Sprintf(" (tháng thiếu, chuyển sang ngày %d tháng %s)", actual.Day, monthName(actual.Month, actual.Leap))
+			return event, short, nil
 		}
 	}
+	return event, nil, err
+}
+
+// formatLunarDate formats ld the way custom events are written, e.g.
+// "29/11/2026", "15/6n/2025" or "1/1" for a date without a year.
+func formatLunarDate(ld LunarDate) string {
+	s := fmt.Sprintf("%d/%d", ld.Day, ld.Month)
+	if ld.Leap {
+		s += "n"
+	}
+	if ld.Year != 0 {
+		s += fmt.Sprintf("/%d", ld.Year)
+	}
+	return s
+}
 
-	return events, nil
+func monthName(month int, leap bool) string {
+	if leap {
+		return fmt.Sprintf("%d nhuận", month)
+	}
+	return fmt.Sprintf("%d", month)
 }
@@ -1,8 +1,10 @@
 package calendar_test
 
 import (
+	"fmt"
 	"maps"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +12,10 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// withoutFestivals leaves out the built-in festivals, so that tests of
+// custom events only see their own events.
+var withoutFestivals = calendar.WithoutFestivals(calendar.FestivalIDs...)
+
 func findEventByTitle(events []calendar.Event, title string) *calendar.Event {
 	for i := range events {
 		if events[i].Title == title {
@@ -41,9 +47,46 @@ func TestGenerator_Generate(t *testing.T) {
 	})
 }
 
+func TestGenerator_Events(t *testing.T) {
+	t.Run("yields the events of Generate", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi", calendar.WithSolarTerms(), calendar.WithMoonPhases())
+		events, err := gen.Generate("10/3:Giỗ ông")
+		require.NoError(t, err)
+
+		seq, err := gen.Events("10/3:Giỗ ông")
+
+		require.NoError(t, err)
+		require.Equal(t, events, slices.Collect(seq))
+	})
+
+	t.Run("stops when the consumer stops", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 200, "Asia/Hanoi", calendar.WithMoonPhases())
+		seq, err := gen.Events("")
+		require.NoError(t, err)
+
+		var first []calendar.Event
+		for e := range seq {
+			first = append(first, e)
+			if len(first) == 3 {
+				break
+			}
+		}
+
+		require.Len(t, first, 3)
+	})
+
+	t.Run("reports invalid custom events before iterating", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+
+		_, err := gen.Events("31/1:Invalid")
+
+		require.Error(t, err)
+	})
+}
+
 func TestGenerator_CustomEvents(t *testing.T) {
 	t.Run("parses recurring custom event", func(t *testing.T) {
-		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi")
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi", withoutFestivals)
 		events, err := gen.Generate("15/8:My Birthday")
 
 		require.NoError(t, err)
@@ -57,11 +100,25 @@ func TestGenerator_CustomEvents(t *testing.T) {
 		require.Equal(t, "My Birthday", events[1].Title)
 		require.Equal(t, 2027, events[1].Date.Year())
 		require.Equal(t, time.September, events[1].Date.Month())
-		require.Equal(t, 14, events[1].Date.Day())
+		require.Equal(t, 15, events[1].Date.Day())
+	})
+
+	t.Run("emits every occurrence of month 12 within the Gregorian span", func(t *testing.T) {
+		// Lunar 3/12 falls on both 10/1/2027 (lunar year 2026) and
+		// 30/12/2027 (lunar year 2027)
+		gen := calendar.NewGenerator(2027, 1, "Asia/Ho_Chi_Minh", withoutFestivals)
+		events, err := gen.Generate("3/12:Event")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, time.January, events[0].Date.Month())
+		require.Equal(t, 10, events[0].Date.Day())
+		require.Equal(t, time.December, events[1].Date.Month())
+		require.Equal(t, 30, events[1].Date.Day())
 	})
 
 	t.Run("parses single year custom event", func(t *testing.T) {
-		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi")
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi", withoutFestivals)
 		events, err := gen.Generate("15/8/2027:One Time Event")
 
 		require.NoError(t, err)
@@ -69,11 +126,11 @@ func TestGenerator_CustomEvents(t *testing.T) {
 		require.Equal(t, "One Time Event", events[0].Title)
 		require.Equal(t, 2027, events[0].Date.Year())
 		require.Equal(t, time.September, events[0].Date.Month())
-		require.Equal(t, 14, events[0].Date.Day())
+		require.Equal(t, 15, events[0].Date.Day())
 	})
 
 	t.Run("parses multiple custom events", func(t *testing.T) {
-		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
 		events, err := gen.Generate("4/5:Event 1,15/8:Event 2")
 
 		require.NoError(t, err)
@@ -90,33 +147,175 @@ func TestGenerator_CustomEvents(t *testing.T) {
 		require.Equal(t, 25, events[1].Date.Day())
 	})
 
+	t.Run("parses leap month custom event", func(t *testing.T) {
+		gen := calendar.NewGenerator(2025, 2, "Asia/Ho_Chi_Minh", withoutFestivals)
+		events, err := gen.Generate("15/6n:Leap Event,15/6:Regular Event")
+
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+
+		require.Equal(t, "Leap Event", events[0].Title)
+		require.True(t, events[0].LunarDate.Leap)
+		require.Equal(t, 2025, events[0].Date.Year())
+		require.Equal(t, time.August, events[0].Date.Month())
+		require.Equal(t, 8, events[0].Date.Day())
+
+		require.Equal(t, "Regular Event", events[1].Title)
+		require.False(t, events[1].LunarDate.Leap)
+		require.Equal(t, 2025, events[1].Date.Year())
+		require.Equal(t, time.July, events[1].Date.Month())
+		require.Equal(t, 9, events[1].Date.Day())
+	})
+
 	t.Run("invalid format returns error", func(t *testing.T) {
-		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
 		_, err := gen.Generate("invalid")
 
 		require.Error(t, err)
 	})
 
 	t.Run("empty title returns error", func(t *testing.T) {
-		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
 		_, err := gen.Generate("15/8:")
 
 		require.Error(t, err)
 	})
 
 	t.Run("missing day returns error", func(t *testing.T) {
-		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
 		_, err := gen.Generate("/8:Event")
 
 		require.Error(t, err)
 	})
 
 	t.Run("missing month returns error", func(t *testing.T) {
-		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
 		_, err := gen.Generate("15/:Event")
 
 		require.Error(t, err)
 	})
+
+	t.Run("out of range day or month returns error", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
+
+		_, err := gen.Generate("31/8:Event")
+		require.ErrorContains(t, err, "at most 30 days")
+
+		_, err = gen.Generate("1/13:Event")
+		require.ErrorContains(t, err, "at most 12 months")
+	})
+
+	t.Run("day 30 of a short month in a specific year returns error with the error policy", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
+		_, err := gen.Generate("30/2/2026@error:Event")
+
+		require.ErrorContains(t, err, "month 2 of lunar year 2026 has only 29 days")
+	})
+
+	t.Run("missing leap month in a specific year returns error", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
+		_, err := gen.Generate("15/6n/2026:Event")
+
+		require.ErrorContains(t, err, "lunar year 2026 has no leap month 6")
+	})
+
+	t.Run("recurring event skips years without the date", func(t *testing.T) {
+		// Month 7 has 30 days in lunar year 2025 but only 29 in 2026
+		gen := calendar.NewGenerator(2025, 2, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("30/7:Event")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, time.Date(2025, time.September, 21, 0, 0, 0, 0, events[0].Date.Location()), events[0].Date)
+	})
+
+	t.Run("recurring leap month event without any occurrence is left out", func(t *testing.T) {
+		// None of the lunar years 2026 to 2035 has a leap month 6
+		gen := calendar.NewGenerator(2026, 10, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("15/6n:Event")
+
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+}
+
+func TestGenerator_Timezone(t *testing.T) {
+	t.Run("Asia/Shanghai generates the Chinese calendar", func(t *testing.T) {
+		vietnamese, err := calendar.NewGenerator(2007, 1, "Asia/Hanoi").Generate("")
+		require.NoError(t, err)
+		chinese, err := calendar.NewGenerator(2007, 1, "Asia/Shanghai").Generate("")
+		require.NoError(t, err)
+
+		require.Equal(t, 17, findEventByTitle(vietnamese, "Tết Nguyên Đán").Date.Day())
+		require.Equal(t, 18, findEventByTitle(chinese, "Tết Nguyên Đán").Date.Day())
+	})
+}
+
+func TestGenerator_WithSolarTerms(t *testing.T) {
+	t.Run("adds the 24 solar terms to the default events", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithSolarTerms())
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.NotNil(t, findEventByTitle(events, "Tết Nguyên Đán"))
+
+		thanhMinh := findEventByTitle(events, "Tiết Thanh Minh")
+		require.NotNil(t, thanhMinh)
+		require.Equal(t, time.April, thanhMinh.Date.Month())
+		require.Equal(t, 5, thanhMinh.Date.Day())
+		require.False(t, thanhMinh.LunarDate.Show)
+
+		count := 0
+		for _, e := range events {
+			if strings.HasPrefix(e.Title, "Tiết ") {
+				count++
+			}
+		}
+		require.Equal(t, 24, count)
+	})
+
+	t.Run("adds the solar terms to custom events", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithSolarTerms())
+		events, err := gen.Generate("15/8:My Birthday")
+
+		require.NoError(t, err)
+		require.Len(t, events, 25)
+	})
+
+	t.Run("omitted by default", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Nil(t, findEventByTitle(events, "Tiết Thanh Minh"))
+	})
+}
+
+func TestGenerator_WithMoonPhases(t *testing.T) {
+	gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithMoonPhases())
+	events, err := gen.Generate("")
+	require.NoError(t, err)
+
+	var newMoons, fullMoons []calendar.Event
+	for _, e := range events {
+		switch e.Title {
+		case "Trăng non":
+			newMoons = append(newMoons, e)
+		case "Trăng tròn":
+			fullMoons = append(fullMoons, e)
+		}
+	}
+
+	// 2026 has a blue moon in May
+	require.Len(t, newMoons, 12)
+	require.Len(t, fullMoons, 13)
+
+	// The new moon of 17/2/2026 was at 12:01 UTC, i.e. 19:01 in Hanoi
+	newMoon := newMoons[1]
+	require.True(t, newMoon.Timed)
+	require.Equal(t, "Asia/Hanoi", newMoon.Date.Location().String())
+	require.Equal(t, "2026-02-17 19", newMoon.Date.Format("2006-01-02 15"))
+	require.Equal(t, 1, newMoon.LunarDate.Day)
 }
 
 func TestGenerator_DefaultEvents(t *testing.T) {
@@ -164,3 +363,295 @@ func TestEvent_LunarDate(t *testing.T) {
 		require.False(t, event.LunarDate.Show)
 	})
 }
+
+func BenchmarkGenerator_Generate(b *testing.B) {
+	customEvents := "4/5:Event 1,15/8:Event 2,30/12:Event 3,10/3:Event 4,1/1:Event 5,23/12:Event 6,15/7:Event 7,9/9:Event 8"
+
+	for _, years := range []int{10, 100, 200} {
+		b.Run(fmt.Sprintf("default events/%d years", years), func(b *testing.B) {
+			gen := calendar.NewGenerator(1950, years, "Asia/Ho_Chi_Minh")
+			for b.Loop() {
+				_, err := gen.Generate("")
+				require.NoError(b, err)
+			}
+		})
+
+		b.Run(fmt.Sprintf("custom events/%d years", years), func(b *testing.B) {
+			gen := calendar.NewGenerator(1950, years, "Asia/Ho_Chi_Minh")
+			for b.Loop() {
+				_, err := gen.Generate(customEvents)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+func TestGenerator_MissingDatePolicy(t *testing.T) {
+	// Month 7 of lunar year 2026 has only 29 days, from Aug 13 to Sep 10
+	t.Run("skips short months by default and reports them", func(t *testing.T) {
+		gen := calendar.NewGenerator(2025, 2, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("30/7:Giỗ ông")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, 2025, events[0].Date.Year())
+
+		warnings := gen.Warnings()
+		require.Len(t, warnings, 1)
+		require.Equal(t, "Giỗ ông", warnings[0].Title)
+		require.Equal(t, 2026, warnings[0].Year)
+		require.Equal(t, "Giỗ ông: month 7 of lunar year 2026 has only 29 days, skipped", warnings[0].String())
+	})
+
+	// Month 12 of lunar year 2025, in January and February 2026, and month
+	// 12 of lunar year 2026 have only 29 days too
+	t.Run("skips the only year of the span by default", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("30/12:Tất niên")
+
+		require.NoError(t, err)
+		require.Empty(t, events)
+		require.Len(t, gen.Warnings(), 1)
+		require.Equal(t, "month 12 of lunar year 2025 has only 29 days, skipped", gen.Warnings()[0].Message)
+	})
+
+	t.Run("skips an event that happens once in a short month", func(t *testing.T) {
+		for _, custom := range []string{"30/12/2026:Tất niên", "30/12/2026@skip:Tất niên"} {
+			gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithMissingDatePolicy(calendar.MissingDateSkip))
+			events, err := gen.Generate(custom)
+
+			require.NoError(t, err, custom)
+			require.Empty(t, events, custom)
+			require.Len(t, gen.Warnings(), 1, custom)
+			require.Equal(t, "month 12 of lunar year 2026 has only 29 days, skipped", gen.Warnings()[0].Message)
+		}
+	})
+
+	t.Run("moves to the last day of the month", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithMissingDatePolicy(calendar.MissingDateLastDay))
+		events, err := gen.Generate("30/7:Giỗ ông")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, time.Date(2026, time.September, 10, 0, 0, 0, 0, events[0].Date.Location()), events[0].Date)
+		require.Equal(t, 29, events[0].LunarDate.Day)
+		require.Equal(t, 7, events[0].LunarDate.Month)
+		require.Contains(t, events[0].Description, "tháng thiếu")
+
+		require.Len(t, gen.Warnings(), 1)
+		require.Equal(t, "month 7 of lunar year 2026 has only 29 days, moved to 29/7/2026", gen.Warnings()[0].Message)
+	})
+
+	t.Run("per event policy overrides the global one", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithMissingDatePolicy(calendar.MissingDateLastDay))
+		events, err := gen.Generate("30/7@next:Giỗ ông")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, time.Date(2026, time.September, 11, 0, 0, 0, 0, events[0].Date.Location()), events[0].Date)
+		require.Equal(t, 1, events[0].LunarDate.Day)
+		require.Equal(t, 8, events[0].LunarDate.Month)
+	})
+
+	t.Run("next day after month 12 is Tết", func(t *testing.T) {
+		gen := calendar.NewGenerator(2027, 1, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("30/12/2026@next:Tất niên")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, time.Date(2027, time.February, 6, 0, 0, 0, 0, events[0].Date.Location()), events[0].Date)
+		require.Equal(t, calendar.LunarDate{Year: 2027, Day: 1, Month: 1, Show: true}, events[0].LunarDate)
+	})
+
+	t.Run("error policy fails on a short month within the span", func(t *testing.T) {
+		gen := calendar.NewGenerator(2025, 2, "Asia/Hanoi", withoutFestivals)
+		_, err := gen.Generate("30/7@error:Giỗ ông")
+
+		require.ErrorContains(t, err, "month 7 of lunar year 2026 has only 29 days")
+	})
+
+	t.Run("error policy ignores short months outside the span", func(t *testing.T) {
+		gen := calendar.NewGenerator(2025, 1, "Asia/Hanoi", withoutFestivals, calendar.WithMissingDatePolicy(calendar.MissingDateError))
+		events, err := gen.Generate("30/7:Giỗ ông")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+	})
+
+	t.Run("unknown policy returns error", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
+		_, err := gen.Generate("30/7@later:Giỗ ông")
+
+		require.ErrorContains(t, err, "unknown missing date policy")
+	})
+}
+
+func TestParseMissingDatePolicy(t *testing.T) {
+	for _, policy := range []calendar.MissingDatePolicy{
+		calendar.MissingDateSkip,
+		calendar.MissingDateLastDay,
+		calendar.MissingDateNextDay,
+		calendar.MissingDateError,
+	} {
+		parsed, err := calendar.ParseMissingDatePolicy(policy.String())
+		require.NoError(t, err)
+		require.Equal(t, policy, parsed)
+	}
+
+	_, err := calendar.ParseMissingDatePolicy("nope")
+	require.Error(t, err)
+}
+
+func TestGenerator_WithAnniversaries(t *testing.T) {
+	t.Run("death in a leap month is commemorated in the regular month", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 3, "Asia/Hanoi", calendar.WithAnniversaries(calendar.Anniversary{
+			Name:       "Bà Ngoại",
+			LunarDeath: calendar.LunarDate{Year: 2025, Day: 10, Month: 6, Leap: true},
+		}))
+		events, err := gen.Generate("")
+		require.NoError(t, err)
+
+		first := findEventByTitle(events, "Giỗ đầu Bà Ngoại")
+		require.NotNil(t, first)
+		require.Equal(t, time.Date(2026, time.July, 23, 0, 0, 0, 0, first.Date.Location()), first.Date)
+		require.Equal(t, calendar.LunarDate{Year: 2026, Day: 10, Month: 6, Show: true}, first.LunarDate)
+		require.Contains(t, first.Description, "tiểu tường")
+		require.Contains(t, first.Description, "ngày mất 10/6n/2025")
+
+		second := findEventByTitle(events, "Giỗ hết Bà Ngoại")
+		require.NotNil(t, second)
+		require.Equal(t, 2027, second.LunarDate.Year)
+		require.Contains(t, second.Description, "đại tường")
+
+		third := findEventByTitle(events, "Giỗ Bà Ngoại (3 năm)")
+		require.NotNil(t, third)
+		require.Equal(t, 2028, third.LunarDate.Year)
+	})
+
+	t.Run("solar date of death is converted to the lunar date", func(t *testing.T) {
+		// 17 Feb 2026 is Tết, 1/1 of lunar year 2026
+		gen := calendar.NewGenerator(2027, 1, "Asia/Hanoi", calendar.WithAnniversaries(calendar.Anniversary{
+			Name:       "Ông Nội",
+			SolarDeath: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC),
+		}))
+		events, err := gen.Generate("")
+		require.NoError(t, err)
+
+		first := findEventByTitle(events, "Giỗ đầu Ông Nội")
+		require.NotNil(t, first)
+		require.Equal(t, time.Date(2027, time.February, 6, 0, 0, 0, 0, first.Date.Location()), first.Date)
+	})
+
+	t.Run("no giỗ before the first anniversary", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithAnniversaries(calendar.Anniversary{
+			Name:       "Ông Nội",
+			LunarDeath: calendar.LunarDate{Year: 2026, Day: 5, Month: 3},
+		}))
+		events, err := gen.Generate("")
+		require.NoError(t, err)
+
+		for _, e := range events {
+			require.NotContains(t, e.Title, "Ông Nội")
+		}
+	})
+
+	t.Run("day 30 moves to day 29 in short months", func(t *testing.T) {
+		// Month 7 has 30 days in lunar year 2025 but only 29 in 2026
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithAnniversaries(calendar.Anniversary{
+			Name:       "Ông Nội",
+			LunarDeath: calendar.LunarDate{Year: 2025, Day: 30, Month: 7},
+		}))
+		events, err := gen.Generate("")
+		require.NoError(t, err)
+
+		first := findEventByTitle(events, "Giỗ đầu Ông Nội")
+		require.NotNil(t, first)
+		require.Equal(t, time.Date(2026, time.September, 10, 0, 0, 0, 0, first.Date.Location()), first.Date)
+		require.Len(t, gen.Warnings(), 1)
+	})
+
+	t.Run("nonexistent lunar date of death returns error", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithAnniversaries(calendar.Anniversary{
+			Name:       "Ông Nội",
+			LunarDeath: calendar.LunarDate{Year: 2026, Day: 10, Month: 6, Leap: true},
+		}))
+		_, err := gen.Generate("")
+
+		require.ErrorContains(t, err, "lunar year 2026 has no leap month 6")
+	})
+}
+
+func TestParseAnniversaries(t *testing.T) {
+	anniversaries, err := calendar.ParseAnniversaries("2020-03-15:Ông Nội, 10/6n/2025:Bà Ngoại")
+
+	require.NoError(t, err)
+	require.Equal(t, []calendar.Anniversary{
+		{Name: "Ông Nội", SolarDeath: time.Date(2020, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{Name: "Bà Ngoại", LunarDeath: calendar.LunarDate{Year: 2025, Day: 10, Month: 6, Leap: true}},
+	}, anniversaries)
+
+	t.Run("names with commas and colons are quoted or escaped", func(t *testing.T) {
+		anniversaries, err := calendar.ParseAnniversaries(`2020-03-15:"Bà Ngoại, Huệ", 10/3/2023:Ông\: nội`)
+
+		require.NoError(t, err)
+		require.Equal(t, []calendar.Anniversary{
+			{Name: "Bà Ngoại, Huệ", SolarDeath: time.Date(2020, time.March, 15, 0, 0, 0, 0, time.UTC)},
+			{Name: "Ông: nội", LunarDeath: calendar.LunarDate{Year: 2023, Day: 10, Month: 3}},
+		}, anniversaries)
+	})
+
+	for _, invalid := range []string{"2020-03-15", "2020-03-15:", "10/6:Ông Nội", "31/6/2025:Ông Nội", "last/6/2025:Ông Nội", "2020-03-15:Ông: nội"} {
+		_, err := calendar.ParseAnniversaries(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	t.Run("errors locate the malformed text", func(t *testing.T) {
+		_, err := calendar.ParseAnniversaries(`2020-03-15:Ông Nội, 10/6:Bà Ngoại`)
+
+		require.EqualError(t, err, `invalid anniversary: column 25 near ":": expected the year of death, as yyyy-mm-dd or day/month/year`)
+	})
+}
+
+func TestGenerator_WithFullMoonDays(t *testing.T) {
+	t.Run("adds Rằm of the months without a festival on it", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithFullMoonDays())
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		ram2 := findEventByTitle(events, "Rằm Tháng 2 (Âm lịch)")
+		require.NotNil(t, ram2)
+		require.Equal(t, time.Date(2026, time.April, 2, 0, 0, 0, 0, ram2.Date.Location()), ram2.Date)
+		require.Equal(t, calendar.LunarDate{Year: 2026, Day: 15, Month: 2}, ram2.LunarDate)
+
+		// Tết Thượng Nguyên, Vu Lan and Trung Thu already fall on Rằm
+		require.Nil(t, findEventByTitle(events, "Rằm Tháng 1 (Âm lịch)"))
+		require.Nil(t, findEventByTitle(events, "Rằm Tháng 7 (Âm lịch)"))
+		require.Nil(t, findEventByTitle(events, "Rằm Tháng 8 (Âm lịch)"))
+		require.NotNil(t, findEventByTitle(events, "Vu Lan"))
+	})
+
+	t.Run("keeps Rằm of a month whose festival is excluded", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithFullMoonDays(), calendar.WithoutFestivals(calendar.FestivalVuLan))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.NotNil(t, findEventByTitle(events, "Rằm Tháng 7 (Âm lịch)"))
+	})
+
+	t.Run("custom event on Rằm replaces it", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithFullMoonDays())
+		events, err := gen.Generate("15/10:Tết Hạ Nguyên")
+
+		require.NoError(t, err)
+		require.NotNil(t, findEventByTitle(events, "Tết Hạ Nguyên"))
+		require.Nil(t, findEventByTitle(events, "Rằm Tháng 10 (Âm lịch)"))
+	})
+
+	t.Run("omitted by default", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi")
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Nil(t, findEventByTitle(events, "Rằm Tháng 2 (Âm lịch)"))
+	})
+}
@@ -0,0 +1,122 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
+)
+
+// MissingDatePolicy decides what happens to a custom event in a lunar year
+// whose month is too short for its day, e.g. day 30 of a 29-day month
+// (tháng thiếu).
+type MissingDatePolicy int
+
+const (
+	// MissingDateSkip leaves the year out.
+	MissingDateSkip MissingDatePolicy = iota
+	// MissingDateLastDay moves the event to the last day of the month, the
+	// way most families observe a giỗ on day 30 in a short month.
+	MissingDateLastDay
+	// MissingDateNextDay moves the event to the day after the last day of
+	// the month, i.e. day 1 of the following month.
+	MissingDateNextDay
+	// MissingDateError fails generation.
+	MissingDateError
+)
+
+var missingDatePolicyNames = [...]string{"skip", "last", "next", "error"}
+
+func (p MissingDatePolicy) String() string {
+	if p < 0 || int(p) >= len(missingDatePolicyNames) {
+		return fmt.Sprintf("MissingDatePolicy(%d)", int(p))
+	}
+	return missingDatePolicyNames[p]
+}
+
+// ParseMissingDatePolicy parses a policy name: skip, last, next or error.
+func ParseMissingDatePolicy(name string) (MissingDatePolicy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, n := range missingDatePolicyNames {
+		if n == name {
+			return MissingDatePolicy(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown missing date policy %q, expected one of %s", name, strings.Join(missingDatePolicyNames[:], ", "))
+}
+
+// WithMissingDatePolicy sets the policy for custom events that do not name
+// their own. The default is MissingDateSkip.
+func WithMissingDatePolicy(policy MissingDatePolicy) Option {
+	return func(g *Generator) {
+		g.missingDate = policy
+	}
+}
+
+// Warning reports a custom event occurrence that was moved or skipped
+// because its month is too short.
+type Warning struct {
+	Title   string
+	Year    int // lunar year
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Title + ": " + w.Message
+}
+
+// shortMonthError is returned when a lunar month does not have the
+// requested day.
+type shortMonthError struct {
+	year  int
+	month lunar.Month
+}
+
+func (e *shortMonthError) Error() string {
+	return fmt.Sprintf("month %s of lunar year %d has only %d days", monthName(e.month.Month, e.month.Leap), e.year, e.month.Days)
+}
+
+// resolveLunarDate returns the solar date of ld in the lunar year, or an
+// error explaining why the year does not have that date. When the month is
+// too short, the error is a *shortMonthError and the date is the one the day
+// would have had, so callers can tell whether the year matters at all.
+func (g *Generator) resolveLunarDate(year int, ld lunar.Date) (time.Time, error) {
+	info := lunar.YearInfo(year, lunar.WithTimezone(g.timezone))
+	month, ok := info.Month(ld.Month, ld.Leap)
+	if !ok {
+		return time.Time{}, fmt.Errorf("lunar year %d has no leap month %d", year, ld.Month)
+	}
+	date := month.Start.AddDate(0, 0, ld.Day-1)
+	if ld.Day > month.Days {
+		return date, &shortMonthError{year: year, month: month}
+	}
+	return date, nil
+}
+
+// moveMissingDate applies policy to a day its month does not have, returning
+// the solar and lunar dates the event moves to, or false if it is not moved.
+func (g *Generator) moveMissingDate(short *shortMonthError, policy MissingDatePolicy) (time.Time, LunarDate, bool) {
+	month := short.month
+	switch policy {
+	case MissingDateLastDay:
+		return month.Start.AddDate(0, 0, month.Days-1),
+			LunarDate{Year: short.year, Day: month.Days, Month: month.Month, Leap: month.Leap, Show: true}, true
+	case MissingDateNextDay:
+		next := month.Start.AddDate(0, 0, month.Days)
+		// The day after month 12 is Tết of the next lunar year
+		year, ld := lunar.FromSolar(next, lunar.WithTimezone(g.timezone))
+		return next, LunarDate{Year: year, Day: ld.Day, Month: ld.Month, Leap: ld.Leap, Show: true}, true
+	}
+	return time.Time{}, LunarDate{}, false
+}
+
+// Warnings returns the occurrences the last Generate or Events call moved or
+// skipped because their month was too short.
+func (g *Generator) Warnings() []Warning {
+	return g.warnings
+}
+
+func (g *Generator) warn(title string, short *shortMonthError, action string) {
+	g.warnings = append(g.warnings, Warning{Title: title, Year: short.year, Message: short.Error() + ", " + action})
+}
@@ -0,0 +1,504 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The custom events of the -events flag follow this grammar:
+//
+//	events      = [ event ] { "," [ event ] }
+//	event       = anchor [ offset ] [ duration ] [ "@" policy ] ":" text [ ":" text ]
+//	anchor      = date | "{" name "}"
+//	date        = ( number | "last" ) "/" number [ "n" ] [ "/" number ]
+//	offset      = ( "+" | "-" ) number "d"
+//	duration    = "+" number
+//	name        = { any character but "}" }
+//	text        = quoted | bare
+//	quoted      = `"` { any character but `"` and `\` | `\` any character } `"`
+//	bare        = { any character but "," ":" and `\` | `\` any character }
+//
+// The first text is the title and the second, optional one the description.
+// "last" is the last day of the month, and a name in braces anchors the event
+// on another event or a solar term, e.g. "{giao-thua}-1d" or "{Thanh Minh}".
+// A duration makes the event last several days, e.g. "1/1+5" from Mùng 1 to
+// Mùng 5.
+// Spaces around tokens are ignored, and spaces around bare text trimmed.
+//
+// The anniversaries of the -gio flag share the dates and text:
+//
+//	anniversaries = [ anniversary ] { "," [ anniversary ] }
+//	anniversary   = ( solar | date ) ":" text
+//	solar         = number "-" number "-" number
+//
+// where text is the name and the date has a year.
+
+// ParseError reports where a custom event string is malformed.
+type ParseError struct {
+	Column int    // 1-based, counted in characters
+	Token  string // the offending token, empty at the end of the input
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("column %d at end of input: %s", e.Column, e.Msg)
+	}
+	return fmt.Sprintf("column %d near %q: %s", e.Column, e.Token, e.Msg)
+}
+
+// ParseEvents parses custom events written as day/month:title (recurring
+// yearly) or day/month/year:title (single lunar year), separated by commas,
+// e.g. `30/11@last:"Giỗ bà: nội":Nấu cỗ, 15/8:Sinh nhật Lan\, Huệ,
+// 10/3/2025+48d:Cúng 49 ngày`.
+func ParseEvents(s string) ([]Rule, error) {
+	return parseItems(s, (*parser).event, "events")
+}
+
+// parseItems parses the items read by item, separated by commas and
+// skipping empty ones.
+func parseItems[T any](s string, item func(*parser) (T, error), plural string) ([]T, error) {
+	p := &parser{input: []rune(s)}
+	var items []T
+	for {
+		p.skipSpaces()
+		if p.done() {
+			return items, nil
+		}
+		if p.peek() != ',' {
+			v, err := item(p)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+			p.skipSpaces()
+			if p.done() {
+				return items, nil
+			}
+			if p.peek() != ',' {
+				return nil, p.errorf(p.pos, `expected "," between %s`, plural)
+			}
+		}
+		p.pos++
+	}
+}
+
+// ParseLunarDate parses a lunar date written as day/month, or day/month/year
+// for a date in one lunar year only. A month followed by "n" is a leap month,
+// e.g. "15/6n/2025".
+func ParseLunarDate(s string) (LunarDate, error) {
+	date, _, err := parseDate(s, false)
+	return date, err
+}
+
+// ParseRuleDate parses the lunar date of a rule, written like ParseLunarDate
+// expects or as last/month[/year] for the last day of the month.
+func ParseRuleDate(s string) (date LunarDate, lastDay bool, err error) {
+	return parseDate(s, true)
+}
+
+// FormatRuleDate formats the lunar date of a rule the way ParseRuleDate
+// reads it, e.g. "15/6n/2025" or "last/12".
+func FormatRuleDate(date LunarDate, lastDay bool) string {
+	if lastDay {
+		return formatLastDay(date)
+	}
+	return formatLunarDate(date)
+}
+
+// FormatEvents formats lunar rules the way ParseEvents reads them. Rules on
+// a solar date or a solar term, and rules on a lunar date with a year that
+// recur, have no such syntax.
+func FormatEvents(rules []Rule) (string, error) {
+	events := make([]string, len(rules))
+	for i, r := range rules {
+		var b strings.Builder
+		switch {
+		case !r.Solar.IsZero() || r.SolarTerm != "":
+			return "", fmt.Errorf("%s: only lunar events can be written as custom events", r.Title)
+		case r.RelativeTo != "":
+			b.WriteString("{" + r.RelativeTo + "}")
+		case r.Recurring && r.Lunar.Year != 0:
+			return "", fmt.Errorf("%s: a yearly event from a given year cannot be written as a custom event", r.Title)
+		default:
+			b.WriteString(FormatRuleDate(r.Lunar, r.LastDay))
+		}
+		if r.Offset != 0 {
+			fmt.Fprintf(&b, "%+dd", r.Offset)
+		}
+		if r.Days > 1 {
+			fmt.Fprintf(&b, "+%d", r.Days)
+		}
+		if r.MissingDate != nil {
+			b.WriteString("@" + r.MissingDate.String())
+		}
+		b.WriteString(":" + formatText(r.Title))
+		if r.Description != "" {
+			b.WriteString(":" + formatText(r.Description))
+		}
+		events[i] = b.String()
+	}
+	return strings.Join(events, ", "), nil
+}
+
+// formatText quotes text that would not read back as bare text.
+func formatText(s string) string {
+	if !strings.ContainsAny(s, `,:"\`) && s == strings.TrimSpace(s) {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func parseDate(s string, allowLast bool) (LunarDate, bool, error) {
+	p := &parser{input: []rune(s)}
+	p.skipSpaces()
+	start := p.pos
+	date, lastDay, err := p.date()
+	if err == nil && lastDay && !allowLast {
+		err = p.errorf(start, "expected the day")
+	}
+	if err == nil {
+		p.skipSpaces()
+		if !p.done() {
+			err = p.errorf(p.pos, "unexpected text after the date")
+		}
+	}
+	if err != nil {
+		return LunarDate{}, false, fmt.Errorf("invalid date %q: %w", strings.TrimSpace(s), err)
+	}
+	return date, lastDay, nil
+}
+
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() rune {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpaces() {
+	for !p.done() && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// errorf returns a ParseError for the token starting at pos.
+func (p *parser) errorf(pos int, format string, args ...any) *ParseError {
+	return &ParseError{Column: pos + 1, Token: p.tokenAt(pos), Msg: fmt.Sprintf(format, args...)}
+}
+
+// tokenAt returns the run of digits or letters starting at pos, or the single
+// character there.
+func (p *parser) tokenAt(pos int) string {
+	if pos >= len(p.input) {
+		return ""
+	}
+	end := pos + 1
+	switch r := p.input[pos]; {
+	case unicode.IsDigit(r):
+		for end < len(p.input) && unicode.IsDigit(p.input[end]) {
+			end++
+		}
+	case unicode.IsLetter(r):
+		for end < len(p.input) && unicode.IsLetter(p.input[end]) {
+			end++
+		}
+	}
+	return string(p.input[pos:end])
+}
+
+func (p *parser) event() (Rule, error) {
+	var rule Rule
+	var err error
+	if p.peek() == '{' {
+		if rule.RelativeTo, err = p.name(); err != nil {
+			return Rule{}, err
+		}
+		rule.Recurring = true
+	} else {
+		date, lastDay, err := p.date()
+		if err != nil {
+			return Rule{}, err
+		}
+		rule = Rule{Lunar: date, LastDay: lastDay, Recurring: date.Year == 0}
+	}
+
+	p.skipSpaces()
+	if r := p.peek(); r == '+' || r == '-' {
+		if rule.Offset, rule.Days, err = p.offsetAndDuration(); err != nil {
+			return Rule{}, err
+		}
+		p.skipSpaces()
+	}
+
+	if p.peek() == '@' {
+		p.pos++
+		p.skipSpaces()
+		start := p.pos
+		for !p.done() && unicode.IsLetter(p.peek()) {
+			p.pos++
+		}
+		policy, err := ParseMissingDatePolicy(string(p.input[start:p.pos]))
+		if err != nil {
+			return Rule{}, p.errorf(start, "%v", err)
+		}
+		rule.MissingDate = &policy
+		p.skipSpaces()
+	}
+
+	if p.peek() != ':' {
+		return Rule{}, p.errorf(p.pos, `expected ":" before the title`)
+	}
+	p.pos++
+
+	if rule.Title, err = p.text(); err != nil {
+		return Rule{}, err
+	}
+	if rule.Title == "" {
+		return Rule{}, p.errorf(p.pos, "title cannot be empty")
+	}
+
+	if p.peek() == ':' {
+		p.pos++
+		if rule.Description, err = p.text(); err != nil {
+			return Rule{}, err
+		}
+	}
+	return rule, nil
+}
+
+func (p *parser) anniversary() (Anniversary, error) {
+	start := p.pos
+	for !p.done() && p.peek() != ':' && p.peek() != ',' {
+		p.pos++
+	}
+	var a Anniversary
+	if solar, err := time.Parse(time.DateOnly, strings.TrimSpace(string(p.input[start:p.pos]))); err == nil {
+		a.SolarDeath = solar
+	} else {
+		p.pos = start
+		death, lastDay, err := p.date()
+		switch {
+		case err != nil:
+			return Anniversary{}, err
+		case lastDay:
+			return Anniversary{}, p.errorf(start, "expected the day of death")
+		case death.Year == 0:
+			return Anniversary{}, p.errorf(p.pos, "expected the year of death, as yyyy-mm-dd or day/month/year")
+		}
+		a.LunarDeath = death
+		p.skipSpaces()
+	}
+
+	if p.peek() != ':' {
+		return Anniversary{}, p.errorf(p.pos, `expected ":" before the name`)
+	}
+	p.pos++
+
+	var err error
+	if a.Name, err = p.text(); err != nil {
+		return Anniversary{}, err
+	}
+	if a.Name == "" {
+		return Anniversary{}, p.errorf(p.pos, "name cannot be empty")
+	}
+	return a, nil
+}
+
+// name reads the name of an anchor in braces.
+func (p *parser) name() (string, error) {
+	open := p.pos
+	p.pos++
+	start := p.pos
+	for !p.done() && p.peek() != '}' {
+		p.pos++
+	}
+	if p.done() {
+		return "", p.errorf(open, `expected "}" after the anchor`)
+	}
+	name := strings.TrimSpace(string(p.input[start:p.pos]))
+	p.pos++
+	if name == "" {
+		return "", p.errorf(open, "anchor cannot be empty")
+	}
+	return name, nil
+}
+
+// offsetAndDuration reads an optional signed number of days, e.g. "-1d",
+// followed by an optional duration, e.g. "+5".
+func (p *parser) offsetAndDuration() (offset, days int, err error) {
+	for !p.done() && (p.peek() == '+' || p.peek() == '-') {
+		signPos := p.pos
+		sign := 1
+		if p.peek() == '-' {
+			sign = -1
+		}
+		p.pos++
+		n, err := p.number("expected the number of days")
+		if err != nil {
+			return 0, 0, err
+		}
+		if p.peek() == 'd' {
+			if offset != 0 || days != 0 {
+				return 0, 0, p.errorf(signPos, "the offset comes before the duration")
+			}
+			p.pos++
+			offset = sign * n
+			continue
+		}
+		switch {
+		case sign < 0:
+			return 0, 0, p.errorf(p.pos, `expected "d" after the number of days`)
+		case days != 0:
+			return 0, 0, p.errorf(signPos, "the event already has a duration")
+		case n == 0:
+			return 0, 0, p.errorf(signPos+1, "duration must be greater than 0")
+		}
+		days = n
+	}
+	return offset, days, nil
+}
+
+// date reads a lunar date, reporting whether it is the last day of the month.
+func (p *parser) date() (LunarDate, bool, error) {
+	dayPos := p.pos
+	day, lastDay := 0, false
+	if unicode.IsLetter(p.peek()) {
+		if !strings.EqualFold(p.tokenAt(dayPos), "last") {
+			return LunarDate{}, false, p.errorf(dayPos, "expected the day")
+		}
+		p.pos += len("last")
+		lastDay = true
+	} else {
+		var err error
+		if day, err = p.number("expected the day"); err != nil {
+			return LunarDate{}, false, err
+		}
+	}
+	if p.peek() != '/' {
+		return LunarDate{}, false, p.errorf(p.pos, `expected "/" after the day`)
+	}
+	p.pos++
+
+	monthPos := p.pos
+	month, err := p.number("expected the month")
+	if err != nil {
+		return LunarDate{}, false, err
+	}
+	leap := false
+	if r := p.peek(); r == 'n' || r == 'N' {
+		leap = true
+		p.pos++
+	}
+
+	year, yearPos, hasYear := 0, 0, false
+	if p.peek() == '/' {
+		p.pos++
+		yearPos, hasYear = p.pos, true
+		if year, err = p.number("expected the year"); err != nil {
+			return LunarDate{}, false, err
+		}
+	}
+	if r := p.peek(); unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return LunarDate{}, false, p.errorf(p.pos, "unexpected text in the date")
+	}
+
+	switch {
+	case day == 0 && !lastDay:
+		return LunarDate{}, false, p.errorf(dayPos, "day must be greater than 0")
+	case day > 30:
+		return LunarDate{}, false, p.errorf(dayPos, "lunar months have at most 30 days")
+	case month == 0:
+		return LunarDate{}, false, p.errorf(monthPos, "month must be greater than 0")
+	case month > 12:
+		return LunarDate{}, false, p.errorf(monthPos, "lunar years have at most 12 months")
+	case hasYear && year == 0:
+		return LunarDate{}, false, p.errorf(yearPos, "year must be greater than 0")
+	}
+	return LunarDate{Year: year, Day: day, Month: month, Leap: leap}, lastDay, nil
+}
+
+// number reads a run of decimal digits.
+func (p *parser) number(expected string) (int, error) {
+	start := p.pos
+	for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, p.errorf(start, "%s", expected)
+	}
+	n, err := strconv.Atoi(string(p.input[start:p.pos]))
+	if err != nil || n > 1_000_000 {
+		return 0, p.errorf(start, "number is too large")
+	}
+	return n, nil
+}
+
+// text reads quoted or bare text up to the next unescaped "," or ":".
+func (p *parser) text() (string, error) {
+	p.skipSpaces()
+	if p.peek() == '"' {
+		return p.quoted()
+	}
+
+	var b strings.Builder
+	for !p.done() {
+		r := p.peek()
+		if r == ',' || r == ':' {
+			break
+		}
+		if r == '\\' {
+			if p.pos+1 >= len(p.input) {
+				return "", p.errorf(p.pos, "escape at end of input")
+			}
+			p.pos++
+			r = p.peek()
+		}
+		b.WriteRune(r)
+		p.pos++
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func (p *parser) quoted() (string, error) {
+	open := p.pos
+	p.pos++
+
+	var b strings.Builder
+	for {
+		if p.done() {
+			return "", p.errorf(open, "unterminated quoted text")
+		}
+		r := p.peek()
+		p.pos++
+		if r == '"' {
+			break
+		}
+		if r == '\\' {
+			if p.done() {
+				return "", p.errorf(open, "unterminated quoted text")
+			}
+			r = p.peek()
+			p.pos++
+		}
+		b.WriteRune(r)
+	}
+
+	p.skipSpaces()
+	if r := p.peek(); !p.done() && r != ',' && r != ':' {
+		return "", p.errorf(p.pos, `expected "," or ":" after quoted text`)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,267 @@
+package calendar_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvents(t *testing.T) {
+	last := calendar.MissingDateLastDay
+	next := calendar.MissingDateNextDay
+
+	for _, tc := range []struct {
+		name     string
+		input    string
+		expected []calendar.Rule
+	}{
+		{
+			name:  "recurring and single year events",
+			input: "4/5:XXX,15/8/2026:My Birthday",
+			expected: []calendar.Rule{
+				{Title: "XXX", Lunar: calendar.LunarDate{Day: 4, Month: 5}, Recurring: true},
+				{Title: "My Birthday", Lunar: calendar.LunarDate{Year: 2026, Day: 15, Month: 8}},
+			},
+		},
+		{
+			name:  "leap month and policy",
+			input: " 30/6N @ last : Giỗ ông ",
+			expected: []calendar.Rule{
+				{Title: "Giỗ ông", Lunar: calendar.LunarDate{Day: 30, Month: 6, Leap: true}, Recurring: true, MissingDate: &last},
+			},
+		},
+		{
+			name:  "quoted title with separators",
+			input: `10/3:"Giỗ bà: nội", 1/1:"Sinh nhật Lan, Huệ"`,
+			expected: []calendar.Rule{
+				{Title: "Giỗ bà: nội", Lunar: calendar.LunarDate{Day: 10, Month: 3}, Recurring: true},
+				{Title: "Sinh nhật Lan, Huệ", Lunar: calendar.LunarDate{Day: 1, Month: 1}, Recurring: true},
+			},
+		},
+		{
+			name:  "escapes in bare and quoted text",
+			input: `1/1:Lan\, Huệ\: \\ và \"bạn\",2/2:"say \"hi\" \\o/"`,
+			expected: []calendar.Rule{
+				{Title: `Lan, Huệ: \ và "bạn"`, Lunar: calendar.LunarDate{Day: 1, Month: 1}, Recurring: true},
+				{Title: `say "hi" \o/`, Lunar: calendar.LunarDate{Day: 2, Month: 2}, Recurring: true},
+			},
+		},
+		{
+			name:  "optional description",
+			input: `15/8:Trung Thu:"Mua bánh, đèn lồng",1/1:Tết: Về quê`,
+			expected: []calendar.Rule{
+				{Title: "Trung Thu", Description: "Mua bánh, đèn lồng", Lunar: calendar.LunarDate{Day: 15, Month: 8}, Recurring: true},
+				{Title: "Tết", Description: "Về quê", Lunar: calendar.LunarDate{Day: 1, Month: 1}, Recurring: true},
+			},
+		},
+		{
+			name:  "empty events are ignored",
+			input: " , 1/1:Tết,, ",
+			expected: []calendar.Rule{
+				{Title: "Tết", Lunar: calendar.LunarDate{Day: 1, Month: 1}, Recurring: true},
+			},
+		},
+		{
+			name:  "last day of the month and offsets",
+			input: "last/12:Giao Thừa, last/6n/2025-1d:X, 10/3/2025 +48d @next:Cúng 49 ngày",
+			expected: []calendar.Rule{
+				{Title: "Giao Thừa", Lunar: calendar.LunarDate{Month: 12}, LastDay: true, Recurring: true},
+				{Title: "X", Lunar: calendar.LunarDate{Year: 2025, Month: 6, Leap: true}, LastDay: true, Offset: -1},
+				{Title: "Cúng 49 ngày", Lunar: calendar.LunarDate{Year: 2025, Day: 10, Month: 3}, Offset: 48, MissingDate: &next},
+			},
+		},
+		{
+			name:  "duration",
+			input: "1/1+5:Nghỉ Tết,{giao-thua}-1d+7:Về quê",
+			expected: []calendar.Rule{
+				{Title: "Nghỉ Tết", Lunar: calendar.LunarDate{Day: 1, Month: 1}, Recurring: true, Days: 5},
+				{Title: "Về quê", RelativeTo: "giao-thua", Offset: -1, Days: 7, Recurring: true},
+			},
+		},
+		{
+			name:  "anchored on other events",
+			input: "{giao-thua}-1d:Tất Niên,{ Thanh Minh }:Tảo mộ",
+			expected: []calendar.Rule{
+				{Title: "Tất Niên", RelativeTo: "giao-thua", Offset: -1, Recurring: true},
+				{Title: "Tảo mộ", RelativeTo: "Thanh Minh", Recurring: true},
+			},
+		},
+		{
+			name:     "empty input",
+			input:    "",
+			expected: nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := calendar.ParseEvents(tc.input)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, rules)
+		})
+	}
+}
+
+func TestParseEvents_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{"1x/5:Event", `column 2 near "x": expected "/" after the day`},
+		{"invalid", `column 1 near "invalid": expected the day`},
+		{"/8:Event", `column 1 near "/": expected the day`},
+		{"15/:Event", `column 4 near ":": expected the month`},
+		{"15/8x:Event", `column 5 near "x": unexpected text in the date`},
+		{"15/8/:Event", `column 6 near ":": expected the year`},
+		{"0/8:Event", `column 1 near "0": day must be greater than 0`},
+		{"31/8:Event", `column 1 near "31": lunar months have at most 30 days`},
+		{"1/13:Event", `column 3 near "13": lunar years have at most 12 months`},
+		{"1/1/0:Event", `column 5 near "0": year must be greater than 0`},
+		{"99999999999999999999/1:Event", `column 1 near "99999999999999999999": number is too large`},
+		{"15/8 Event", `column 6 near "Event": expected ":" before the title`},
+		{"15/8:", `column 6 at end of input: title cannot be empty`},
+		{"15/8: ,1/1:Tết", `column 7 near ",": title cannot be empty`},
+		{"30/7@later:Giỗ", `column 6 near "later": unknown missing date policy "later", expected one of skip, last, next, error`},
+		{`1/1:"Tết`, `column 5 near "\"": unterminated quoted text`},
+		{`1/1:"Tết" Nguyên Đán`, `column 11 near "Nguyên": expected "," or ":" after quoted text`},
+		{`1/1:Tết\`, `column 8 near "\\": escape at end of input`},
+		{"1/1:a:b:c", `column 8 near ":": expected "," between events`},
+		{"Tết 1/1:Giỗ", `column 1 near "Tết": expected the day`},
+		{"lastly/12:Giỗ", `column 1 near "lastly": expected the day`},
+		{"{giao-thua:Giỗ", `column 1 near "{": expected "}" after the anchor`},
+		{"{ }:Giỗ", `column 1 near "{": anchor cannot be empty`},
+		{"1/1+:Giỗ", `column 5 near ":": expected the number of days`},
+		{"1/1-3:Giỗ", `column 6 near ":": expected "d" after the number of days`},
+		{"1/1+0:Giỗ", `column 5 near "0": duration must be greater than 0`},
+		{"1/1+3+1d:Giỗ", `column 6 near "+": the offset comes before the duration`},
+		{"1/1+3+2:Giỗ", `column 6 near "+": the event already has a duration`},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := calendar.ParseEvents(tc.input)
+
+			require.EqualError(t, err, tc.expected)
+			var parseErr *calendar.ParseError
+			require.ErrorAs(t, err, &parseErr)
+		})
+	}
+}
+
+func TestParseLunarDate(t *testing.T) {
+	date, err := calendar.ParseLunarDate(" 15/6n/2025 ")
+	require.NoError(t, err)
+	require.Equal(t, calendar.LunarDate{Year: 2025, Day: 15, Month: 6, Leap: true}, date)
+
+	_, err = calendar.ParseLunarDate("1x/5")
+	require.EqualError(t, err, `invalid date "1x/5": column 2 near "x": expected "/" after the day`)
+
+	_, err = calendar.ParseLunarDate("1/5:Tết")
+	require.EqualError(t, err, `invalid date "1/5:Tết": column 4 near ":": unexpected text after the date`)
+
+	_, err = calendar.ParseLunarDate("last/12")
+	require.EqualError(t, err, `invalid date "last/12": column 1 near "last": expected the day`)
+}
+
+func TestParseRuleDate(t *testing.T) {
+	date, lastDay, err := calendar.ParseRuleDate("last/12n")
+	require.NoError(t, err)
+	require.True(t, lastDay)
+	require.Equal(t, calendar.LunarDate{Month: 12, Leap: true}, date)
+
+	date, lastDay, err = calendar.ParseRuleDate("15/8")
+	require.NoError(t, err)
+	require.False(t, lastDay)
+	require.Equal(t, calendar.LunarDate{Day: 15, Month: 8}, date)
+}
+
+func TestFormatEvents(t *testing.T) {
+	last := calendar.MissingDateLastDay
+	rules := []calendar.Rule{
+		{Title: "Sinh nhật mẹ", Lunar: calendar.LunarDate{Day: 16, Month: 4}, Recurring: true},
+		{Title: `Giỗ ông: "nội", bà\ngoại`, Description: " Nấu cỗ ", Lunar: calendar.LunarDate{Day: 30, Month: 11}, Recurring: true, MissingDate: &last},
+		{Title: "Cúng 49 ngày", Lunar: calendar.LunarDate{Year: 2025, Day: 10, Month: 3, Leap: true}, Offset: 48},
+		{Title: "Nghỉ Tết", RelativeTo: "giao-thua", Offset: -1, Days: 7, Recurring: true},
+		{Title: "Tất Niên", Lunar: calendar.LunarDate{Month: 12}, LastDay: true, Recurring: true},
+	}
+
+	s, err := calendar.FormatEvents(rules)
+
+	require.NoError(t, err)
+	require.Equal(t, `16/4:Sinh nhật mẹ, 30/11@last:"Giỗ ông: \"nội\", bà\\ngoại":" Nấu cỗ ", 10/3n/2025+48d:Cúng 49 ngày, {giao-thua}-1d+7:Nghỉ Tết, last/12:Tất Niên`, s)
+	parsed, err := calendar.ParseEvents(s)
+	require.NoError(t, err)
+	require.Equal(t, rules, parsed)
+
+	_, err = calendar.FormatEvents([]calendar.Rule{{Title: "Sinh nhật", Solar: time.Date(1960, time.May, 12, 0, 0, 0, 0, time.UTC)}})
+	require.EqualError(t, err, "Sinh nhật: only lunar events can be written as custom events")
+}
+
+func FuzzParseEvents(f *testing.F) {
+	for _, seed := range []string{
+		"4/5:XXX,15/8/2026:My Birthday",
+		"30/6n@last:Giỗ ông:Nấu cỗ",
+		`10/3:"Giỗ bà: nội", 1/1:Lan\, Huệ`,
+		`1/1:"unterminated`,
+		"1x/5:Event",
+		" , ,",
+		"last/12-1d:Tất Niên,{giao-thua}+2d:Event",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		rules, err := calendar.ParseEvents(input)
+		if err != nil {
+			var parseErr *calendar.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("error is not a *ParseError: %v", err)
+			}
+			if columns := utf8.RuneCountInString(input) + 1; parseErr.Column < 1 || parseErr.Column > columns {
+				t.Fatalf("column %d outside of 1..%d", parseErr.Column, columns)
+			}
+			return
+		}
+
+		for _, r := range rules {
+			if r.Title == "" {
+				t.Fatalf("empty title in %q", input)
+			}
+			if r.RelativeTo != "" {
+				continue
+			}
+			if r.LastDay {
+				r.Lunar.Day = 1
+			}
+			if r.Lunar.Day < 1 || r.Lunar.Day > 30 || r.Lunar.Month < 1 || r.Lunar.Month > 12 {
+				t.Fatalf("invalid date %+v in %q", r.Lunar, input)
+			}
+			if r.Recurring != (r.Lunar.Year == 0) {
+				t.Fatalf("recurring %v with year %d in %q", r.Recurring, r.Lunar.Year, input)
+			}
+		}
+	})
+}
+
+// FuzzParseEvents_QuotedTitle checks that any title survives quoting.
+func FuzzParseEvents_QuotedTitle(f *testing.F) {
+	for _, seed := range []string{"Tết", `Giỗ bà: nội`, `say "hi" \o/`, " , "} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, title string) {
+		if title == "" || !utf8.ValidString(title) {
+			t.Skip()
+		}
+		quoted := `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(title) + `"`
+
+		rules, err := calendar.ParseEvents("1/1:" + quoted + ":" + quoted)
+
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Equal(t, title, rules[0].Title)
+		require.Equal(t, title, rules[0].Description)
+	})
+}
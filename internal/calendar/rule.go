@@ -0,0 +1,290 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
+)
+
+// Rule describes a custom event and produces its occurrences. The event is
+// anchored on a lunar date, the last day of a lunar month, a solar date, a
+// solar term or another event, and Offset moves it from its anchor.
+type Rule struct {
+	// ID identifies the rule across calendar updates, so that editing its
+	// title keeps the UIDs of its events. It defaults to one derived from
+	// the anchor, offset and duration, with the title added when several
+	// rules share them.
+	ID    string
+	Title string
+	// Description replaces the generated description when set.
+	Description string
+	Category    string
+	Alarms      []Alarm
+
+	// Lunar is the lunar date of the event when no other anchor is set. Year
+	// may be 0 for a recurring event.
+	Lunar LunarDate
+	// LastDay anchors the event on the last day of Lunar.Month, the 29th or
+	// the 30th depending on the year. Lunar.Day is ignored.
+	LastDay bool
+	Solar   time.Time
+	// SolarTerm anchors a yearly event on the day of a solar term, e.g.
+	// "Thanh Minh".
+	SolarTerm string
+	// RelativeTo anchors the event on another one: the title of another
+	// rule, or the ID or title of a built-in festival. The event takes the
+	// dates and recurrence of its anchor.
+	RelativeTo string
+	// Offset moves every occurrence by a number of days, e.g. -1 for the day
+	// before the anchor.
+	Offset int
+	// Days is how many days each occurrence lasts, e.g. 5 for a Tết break
+	// from Mùng 1 to Mùng 5; 0 and 1 both mean a single day.
+	Days int
+	// Recurring events happen every year, from the year of their date if it
+	// has one. Other events happen once, in the year of their date.
+	Recurring bool
+	// MissingDate overrides the generator's policy for a lunar day the month
+	// does not have.
+	MissingDate *MissingDatePolicy
+
+	// Source locates the rule for error messages, e.g. "events.yaml:12".
+	Source string
+
+	// anchor describes the event RelativeTo resolved to, for the default
+	// description, e.g. "1 ngày trước Giao Thừa".
+	anchor string
+}
+
+// WithRules adds custom events described by rules, e.g. the ones of a
+// config file. Like events passed to Generate, they are added to the
+// built-in festivals.
+func WithRules(rules ...Rule) Option {
+	return func(g *Generator) {
+		g.rules = append(g.rules, rules...)
+	}
+}
+
+// identifyRules gives the rules without an ID one derived from when they
+// happen, and checks that the IDs given are unique. Rules that would share a
+// derived ID tell apart by their title, then by their order.
+func identifyRules(rules []Rule) ([]Rule, error) {
+	taken := make(map[string]bool, len(rules))
+	derived := make(map[string]int, len(rules))
+	for _, r := range rules {
+		if r.ID == "" {
+			derived[r.key()]++
+			continue
+		}
+		if taken[r.ID] {
+			err := fmt.Errorf("duplicate event ID %q", r.ID)
+			if r.Source != "" {
+				return nil, fmt.Errorf("%s: %w", r.Source, err)
+			}
+			return nil, err
+		}
+		taken[r.ID] = true
+	}
+
+	out := make([]Rule, len(rules))
+	for i, r := range rules {
+		if r.ID == "" {
+			id := r.key()
+			if derived[id] > 1 || taken[id] {
+				id += "/" + r.Title
+			}
+			r.ID = id
+			for n := 2; taken[r.ID]; n++ {
+				r.ID = fmt.Sprintf("%s/%d", id, n)
+			}
+			taken[r.ID] = true
+		}
+		out[i] = r
+	}
+	return out, nil
+}
+
+// key describes when the rule happens, e.g. "rule/15/7", "rule/last/12-1d"
+// or "rule/{giao-thua}+5".
+func (r Rule) key() string {
+	var anchor string
+	switch {
+	case r.RelativeTo != "":
+		anchor = "{" + r.RelativeTo + "}"
+	case r.SolarTerm != "":
+		anchor = "{" + r.SolarTerm + "}"
+	case !r.Solar.IsZero() && r.Recurring:
+		anchor = r.Solar.Format("01-02")
+	case !r.Solar.IsZero():
+		anchor = r.Solar.Format(time.DateOnly)
+	case r.LastDay:
+		anchor = formatLastDay(r.Lunar)
+	default:
+		anchor = formatLunarDate(r.Lunar)
+	}
+	if r.Offset != 0 {
+		anchor += fmt.Sprintf("%+dd", r.Offset)
+	}
+	if r.Days > 1 {
+		anchor += fmt.Sprintf("+%d", r.Days)
+	}
+	return "rule/" + anchor
+}
+
+// ruleEvents returns the occurrences of the rule within the generated years.
+// Events that happen once are returned even outside them.
+func (g *Generator) ruleEvents(r Rule) ([]Event, error) {
+	var events []Event
+	var err error
+	switch {
+	case r.SolarTerm != "":
+		events, err = g.solarTermRuleEvents(r)
+	case !r.Solar.IsZero():
+		events = g.solarRuleEvents(r)
+	default:
+		events, err = g.lunarRuleEvents(r)
+	}
+	if err != nil && r.Source != "" {
+		return nil, fmt.Errorf("%s: %w", r.Source, err)
+	}
+	return events, err
+}
+
+func (g *Generator) lunarRuleEvents(r Rule) ([]Event, error) {
+	policy := g.missingDate
+	if r.MissingDate != nil {
+		policy = *r.MissingDate
+	}
+
+	date := r.Lunar
+	datePart := formatLunarDate(date)
+	if r.LastDay {
+		datePart = formatLastDay(date)
+	} else if date.Day == 0 || date.Month == 0 {
+		return nil, errors.New("invalid date: " + datePart + ", day and month must be greater than 0")
+	}
+	if date.Month == 0 {
+		return nil, errors.New("invalid date: " + datePart + ", month must be greater than 0")
+	}
+	if err := validateLunarDate(date.Day, date.Month); err != nil {
+		return nil, errors.New("invalid date: " + datePart + ", " + err.Error())
+	}
+	template := Event{
+		ID:          r.ID,
+		Title:       r.Title,
+		Description: r.Description,
+		Category:    r.Category,
+		Alarms:      r.Alarms,
+		Days:        r.Days,
+	}
+
+	if !r.Recurring {
+		if date.Year == 0 {
+			return nil, errors.New("invalid date: " + datePart + ", an event that happens once needs a year")
+		}
+		if template.Description == "" {
+			template.Description = r.Title + " - " + capitalize(describeAnchor(r))
+		}
+		event, moved, err := g.lunarOccurrence(template, r, date.Year, policy)
+		var short *shortMonthError
+		if errors.As(err, &short) && policy != MissingDateError {
+			g.warn(r.Title, short, "skipped")
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errors.New("invalid date: " + datePart + ", " + err.Error())
+		}
+		if moved != nil {
+			g.warn(r.Title, moved, "moved to "+formatLunarDate(event.LunarDate))
+		}
+		return []Event{g.shift(event, r.Offset)}, nil
+	}
+
+	if template.Description == "" {
+		template.Description = r.Title + " - " + capitalize(describeAnchor(r))
+	}
+	// Years without the date, such as the many without a given leap month,
+	// are left out, even when that leaves the span without an occurrence
+	var events []Event
+	first, last := g.lunarYears()
+	// An offset can bring occurrences of years outside the span into it
+	extra := offsetYears(r.Offset)
+	for year := max(first-extra, date.Year); year <= last+extra; year++ {
+		event, moved, err := g.lunarOccurrence(template, r, year, policy)
+		var short *shortMonthError
+		if errors.As(err, &short) && g.inSpan(event.Date.AddDate(0, 0, r.Offset)) {
+			if policy == MissingDateError {
+				return nil, errors.New("invalid date: " + datePart + ", " + err.Error())
+			}
+			g.warn(r.Title, short, "skipped")
+		}
+		if err != nil {
+			continue
+		}
+		event = g.shift(event, r.Offset)
+		if g.inSpan(event.Date) {
+			if moved != nil {
+				g.warn(r.Title, moved, "moved to "+formatLunarDate(event.LunarDate))
+			}
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// lunarOccurrence returns the occurrence of a lunar rule in the lunar year,
+// before its offset is applied.
+func (g *Generator) lunarOccurrence(template Event, r Rule, year int, policy MissingDatePolicy) (Event, *shortMonthError, error) {
+	ld := lunar.Date{Day: r.Lunar.Day, Month: r.Lunar.Month, Leap: r.Lunar.Leap}
+	if r.LastDay {
+		month, ok := lunar.YearInfo(year, lunar.WithTimezone(g.timezone)).Month(ld.Month, ld.Leap)
+		if !ok {
+			return Event{}, nil, fmt.Errorf("lunar year %d has no leap month %d", year, ld.Month)
+		}
+		ld.Day = month.Days
+	}
+	return g.customOccurrence(template, year, ld, policy)
+}
+
+// solarRuleEvents returns the occurrences of a rule on a solar date. A
+// recurring event on 29 February only happens in leap years.
+func (g *Generator) solarRuleEvents(r Rule) []Event {
+	loc := g.location()
+	solar := r.Solar
+
+	first, last := solar.Year(), solar.Year()
+	if r.Recurring {
+		extra := offsetYears(r.Offset)
+		first, last = max(g.startYear-extra, solar.Year()), g.startYear+g.yearsAhead-1+extra
+	}
+
+	var events []Event
+	for year := first; year <= last; year++ {
+		date := time.Date(year, solar.Month(), solar.Day(), 0, 0, 0, 0, loc)
+		if date.Month() != solar.Month() {
+			continue
+		}
+		date = date.AddDate(0, 0, r.Offset)
+		if r.Recurring && !g.inSpan(date) {
+			continue
+		}
+		lunarYear, ld := lunar.FromSolar(date, lunar.WithTimezone(g.timezone))
+		description := r.Description
+		if description == "" {
+			description = r.Title + " - " + capitalize(describeAnchor(r))
+		}
+		events = append(events, Event{
+			ID:          r.ID,
+			Title:       r.Title,
+			Date:        date,
+			LunarDate:   LunarDate{Year: lunarYear, Day: ld.Day, Month: ld.Month, Leap: ld.Leap, Show: false},
+			Description: description,
+			Category:    r.Category,
+			Alarms:      r.Alarms,
+			Days:        r.Days,
+		})
+	}
+	return events
+}
@@ -0,0 +1,265 @@
+package calendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_WithRules(t *testing.T) {
+	t.Run("lunar rule carries description, category and alarms", func(t *testing.T) {
+		alarms := []calendar.Alarm{{Before: 24 * time.Hour}}
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi", withoutFestivals, calendar.WithRules(calendar.Rule{
+			Title:       "Giỗ ông",
+			Description: "Nấu cỗ",
+			Category:    "Giỗ",
+			Alarms:      alarms,
+			Lunar:       calendar.LunarDate{Day: 2, Month: 11},
+			Recurring:   true,
+		}))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		for _, e := range events {
+			require.Equal(t, "Giỗ ông", e.Title)
+			require.Equal(t, "Nấu cỗ", e.Description)
+			require.Equal(t, "Giỗ", e.Category)
+			require.Equal(t, alarms, e.Alarms)
+		}
+	})
+
+	t.Run("recurring lunar rule with a year starts that year", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 3, "Asia/Hanoi", withoutFestivals, calendar.WithRules(calendar.Rule{
+			Title:     "Event",
+			Lunar:     calendar.LunarDate{Year: 2027, Day: 15, Month: 8},
+			Recurring: true,
+		}))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, 2027, events[0].LunarDate.Year)
+		require.Equal(t, 2028, events[1].LunarDate.Year)
+	})
+
+	t.Run("solar rule recurs on the same solar date", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 3, "Asia/Hanoi", withoutFestivals, calendar.WithRules(calendar.Rule{
+			Title:     "Sinh nhật",
+			Solar:     time.Date(1960, time.May, 12, 0, 0, 0, 0, time.UTC),
+			Recurring: true,
+		}))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		for i, e := range events {
+			require.Equal(t, 2026+i, e.Date.Year())
+			require.Equal(t, time.May, e.Date.Month())
+			require.Equal(t, 12, e.Date.Day())
+			require.False(t, e.LunarDate.Show)
+		}
+		// 12 May 2026 is 26/3 of lunar year 2026
+		require.Equal(t, calendar.LunarDate{Year: 2026, Day: 26, Month: 3}, events[0].LunarDate)
+	})
+
+	t.Run("solar rule on 29 February only recurs in leap years", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 4, "Asia/Hanoi", withoutFestivals, calendar.WithRules(calendar.Rule{
+			Title:     "Event",
+			Solar:     time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			Recurring: true,
+		}))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, 2028, events[0].Date.Year())
+	})
+
+	t.Run("solar rule happening once", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithRules(calendar.Rule{
+			Title: "Event",
+			Solar: time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC),
+		}))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, time.July, events[0].Date.Month())
+	})
+
+	t.Run("errors are prefixed with the rule source", func(t *testing.T) {
+		policy := calendar.MissingDateError
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithRules(calendar.Rule{
+			Title:       "Event",
+			Lunar:       calendar.LunarDate{Year: 2026, Day: 30, Month: 2},
+			MissingDate: &policy,
+			Source:      "events.yaml:3",
+		}))
+		_, err := gen.Generate("")
+
+		require.EqualError(t, err, "events.yaml:3: invalid date: 30/2/2026, month 2 of lunar year 2026 has only 29 days")
+	})
+
+	t.Run("lunar rule happening once needs a year", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithRules(calendar.Rule{
+			Title: "Event",
+			Lunar: calendar.LunarDate{Day: 1, Month: 2},
+		}))
+		_, err := gen.Generate("")
+
+		require.ErrorContains(t, err, "needs a year")
+	})
+
+	t.Run("rules and custom events are combined", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithRules(calendar.Rule{
+			Title:     "From config",
+			Lunar:     calendar.LunarDate{Day: 2, Month: 2},
+			Recurring: true,
+		}))
+		events, err := gen.Generate("3/3:From flag")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, "From config", events[0].Title)
+		require.Equal(t, "From flag", events[1].Title)
+	})
+}
+
+func TestGenerator_RuleAnchors(t *testing.T) {
+	t.Run("last day of a lunar month follows the length of the month", func(t *testing.T) {
+		gen := calendar.NewGenerator(2024, 2, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("last/12:Cuối năm")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		// Month 12 of lunar year 2023 has 30 days, of 2024 29 days
+		require.Equal(t, "2024-02-09", events[0].Date.Format(time.DateOnly))
+		require.Equal(t, calendar.LunarDate{Year: 2023, Day: 30, Month: 12, Show: true}, events[0].LunarDate)
+		require.Equal(t, "2025-01-28", events[1].Date.Format(time.DateOnly))
+		require.Equal(t, calendar.LunarDate{Year: 2024, Day: 29, Month: 12, Show: true}, events[1].LunarDate)
+		require.Equal(t, "Cuối năm - Ngày cuối tháng 12 âm lịch", events[0].Description)
+	})
+
+	t.Run("offset moves occurrences from their lunar date", func(t *testing.T) {
+		gen := calendar.NewGenerator(2025, 1, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("10/3/2025+48d:Cúng 49 ngày")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		// 10/3/2025 is 7 April 2025
+		require.Equal(t, "2025-05-25", events[0].Date.Format(time.DateOnly))
+		require.Equal(t, calendar.LunarDate{Year: 2025, Day: 28, Month: 4, Show: true}, events[0].LunarDate)
+		require.Equal(t, "Cúng 49 ngày - 48 ngày sau ngày 10 tháng 3 năm 2025 âm lịch", events[0].Description)
+	})
+
+	t.Run("event relative to a built-in festival", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("{giao-thua}-1d:Tất Niên")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, "2026-02-15", events[0].Date.Format(time.DateOnly))
+		require.Equal(t, "Tất Niên - 1 ngày trước Giao Thừa", events[0].Description)
+	})
+
+	t.Run("event relative to another rule adds up the offsets", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithRules(
+			calendar.Rule{Title: "Dọn nhà", RelativeTo: "Ông Táo", Offset: 1},
+			calendar.Rule{Title: "Ông Táo", RelativeTo: "Giao Thừa", Offset: -6},
+		))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, "2026-02-11", events[0].Date.Format(time.DateOnly))
+		require.Equal(t, "2026-02-10", events[1].Date.Format(time.DateOnly))
+	})
+
+	t.Run("event on a solar term", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("{Thanh Minh}:Tảo mộ")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, "2026-04-05", events[0].Date.Format(time.DateOnly))
+		require.Equal(t, 2027, events[1].Date.Year())
+		require.Equal(t, "Tảo mộ - Tiết Thanh Minh", events[0].Description)
+	})
+
+	t.Run("duration is carried to every occurrence", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi", withoutFestivals)
+		events, err := gen.Generate("{giao-thua}+5:Nghỉ Tết")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		for _, e := range events {
+			require.Equal(t, 5, e.Days)
+		}
+		require.Equal(t, "2026-02-16", events[0].Date.Format(time.DateOnly))
+	})
+
+	t.Run("anchor cycle returns error", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
+		_, err := gen.Generate("{B}+1d:A,{A}-1d:B")
+
+		require.EqualError(t, err, `"A" is anchored on itself`)
+	})
+
+	t.Run("unknown anchor returns error", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals)
+		_, err := gen.Generate("{Christmas}:X")
+
+		require.ErrorContains(t, err, `invalid anchor "Christmas"`)
+	})
+}
+
+func TestGenerator_RuleIDs(t *testing.T) {
+	generate := func(t *testing.T, rules ...calendar.Rule) []calendar.Event {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithRules(rules...))
+		events, err := gen.Generate("")
+		require.NoError(t, err)
+		return events
+	}
+
+	t.Run("derived from the date, not the title", func(t *testing.T) {
+		before := generate(t, calendar.Rule{Title: "Giỗ ông", Lunar: calendar.LunarDate{Day: 10, Month: 3}, Recurring: true})
+		after := generate(t, calendar.Rule{Title: "Giỗ ông nội", Lunar: calendar.LunarDate{Day: 10, Month: 3}, Recurring: true})
+
+		require.Equal(t, "rule/10/3", before[0].ID)
+		require.Equal(t, before[0].ID, after[0].ID)
+	})
+
+	t.Run("rules on the same date are told apart by title", func(t *testing.T) {
+		events := generate(t,
+			calendar.Rule{Title: "A", Lunar: calendar.LunarDate{Day: 10, Month: 3}, Recurring: true},
+			calendar.Rule{Title: "B", Lunar: calendar.LunarDate{Day: 10, Month: 3}, Recurring: true},
+			calendar.Rule{Title: "B", Lunar: calendar.LunarDate{Day: 10, Month: 3}, Recurring: true},
+			calendar.Rule{Title: "C", RelativeTo: "A", Offset: -1},
+		)
+
+		var ids []string
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		require.Equal(t, []string{"rule/10/3/A", "rule/10/3/B", "rule/10/3/B/2", "rule/{A}-1d"}, ids)
+	})
+
+	t.Run("explicit IDs are kept", func(t *testing.T) {
+		events := generate(t, calendar.Rule{ID: "gio-ong", Title: "Giỗ ông", Lunar: calendar.LunarDate{Day: 10, Month: 3}, Recurring: true})
+
+		require.Equal(t, "gio-ong", events[0].ID)
+	})
+
+	t.Run("explicit IDs must be unique", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", withoutFestivals, calendar.WithRules(
+			calendar.Rule{ID: "x", Title: "A", Lunar: calendar.LunarDate{Day: 1, Month: 3}, Recurring: true},
+			calendar.Rule{ID: "x", Title: "B", Lunar: calendar.LunarDate{Day: 2, Month: 3}, Recurring: true, Source: "events.yaml:5"},
+		))
+		_, err := gen.Generate("")
+
+		require.EqualError(t, err, `events.yaml:5: duplicate event ID "x"`)
+	})
+}
@@ -0,0 +1,279 @@
+// Package config loads custom events from a YAML or JSON file.
+//
+// A config file lists events, each on a lunar or a solar date:
+//
+//	events:
+//	  - title: Giỗ ông
+//	    lunar: 30/11
+//	    missing_date: last
+//	    category: Giỗ
+//	    alarms: [1d]
+//	  - title: Sinh nhật mẹ
+//	    solar: 1960-05-12
+//	    recurrence: yearly
+//	    description: Mua hoa
+//	  - title: Tất Niên
+//	    relative_to: giao-thua
+//	    offset: -1
+//	  - title: Tảo mộ
+//	    solar_term: Thanh Minh
+//	  - title: Nghỉ Tết
+//	    lunar: 1/1
+//	    days: 5
+//	alarms:
+//	  Giỗ: [3d@08:00, morning]
+//	  "*": 1d
+//
+// Instead of a date, an event can be anchored on the last day of a lunar
+// month (lunar: last/12), a solar term, or another event or built-in
+// festival (relative_to). offset moves it by a number of days, and days
+// makes it last several days.
+//
+// id keeps the UIDs of the events of an entry when its date changes; by
+// default they follow the date, not the title, so a title can be edited
+// freely.
+//
+// alarms sets the alarms of the events of a category, built-in ones such as
+// Giỗ and Lễ Tết included, unless they have their own. "*" stands for every
+// other event.
+//
+// JSON files use the same keys.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the content of a config file.
+type Config struct {
+	Events []calendar.Rule
+	// Alarms are the alarms of the events of each category that have none
+	// of their own. The "*" category holds the alarms of every other event.
+	Alarms map[string][]calendar.Alarm
+}
+
+// Error is a problem with a config file, located by its line.
+type Error struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Load reads the config file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, data)
+}
+
+// Parse parses the content of a config file. name is used in error messages.
+func Parse(name string, data []byte) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	cfg := &Config{}
+	if len(doc.Content) == 0 {
+		return cfg, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, &Error{File: name, Line: root.Line, Err: errors.New("expected a mapping with an events list or alarms")}
+	}
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "events":
+			if value.Kind != yaml.SequenceNode {
+				return nil, &Error{File: name, Line: value.Line, Err: errors.New("events must be a list")}
+			}
+			for _, node := range value.Content {
+				rule, err := parseEvent(name, node)
+				if err != nil {
+					return nil, err
+				}
+				cfg.Events = append(cfg.Events, rule)
+			}
+		case "alarms":
+			if value.Kind != yaml.MappingNode {
+				return nil, &Error{File: name, Line: value.Line, Err: errors.New("alarms must map categories to alarms")}
+			}
+			cfg.Alarms = make(map[string][]calendar.Alarm)
+			for j := 0; j < len(value.Content); j += 2 {
+				alarms, err := parseAlarms(name, value.Content[j+1])
+				if err != nil {
+					return nil, err
+				}
+				cfg.Alarms[value.Content[j].Value] = alarms
+			}
+		default:
+			return nil, &Error{File: name, Line: key.Line, Err: fmt.Errorf("unknown key %q", key.Value)}
+		}
+	}
+	return cfg, nil
+}
+
+func parseEvent(name string, node *yaml.Node) (calendar.Rule, error) {
+	fail := func(n *yaml.Node, format string, args ...any) (calendar.Rule, error) {
+		return calendar.Rule{}, &Error{File: name, Line: n.Line, Err: fmt.Errorf(format, args...)}
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return fail(node, "event must be a mapping")
+	}
+
+	rule := calendar.Rule{Source: fmt.Sprintf("%s:%d", name, node.Line)}
+	var lunarNode, solarNode, termNode, relativeNode, recurrenceNode *yaml.Node
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "alarms" {
+			alarms, err := parseAlarms(name, value)
+			if err != nil {
+				return calendar.Rule{}, err
+			}
+			rule.Alarms = alarms
+			continue
+		}
+
+		if value.Kind != yaml.ScalarNode {
+			return fail(value, "%s must be a single value", key.Value)
+		}
+		switch key.Value {
+		case "id":
+			rule.ID = strings.TrimSpace(value.Value)
+		case "title":
+			rule.Title = strings.TrimSpace(value.Value)
+		case "description":
+			rule.Description = value.Value
+		case "category":
+			rule.Category = strings.TrimSpace(value.Value)
+		case "lunar":
+			lunarNode = value
+		case "solar":
+			solarNode = value
+		case "solar_term":
+			termNode = value
+			rule.SolarTerm = strings.TrimSpace(value.Value)
+		case "relative_to":
+			relativeNode = value
+			rule.RelativeTo = strings.TrimSpace(value.Value)
+		case "days":
+			days, err := strconv.Atoi(strings.TrimSpace(value.Value))
+			if err != nil || days < 1 {
+				return fail(value, "invalid days %q, expected the number of days the event lasts, e.g. 5", value.Value)
+			}
+			rule.Days = days
+		case "offset":
+			offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value.Value), "d"))
+			if err != nil {
+				return fail(value, "invalid offset %q, expected a number of days such as -1 or 49", value.Value)
+			}
+			rule.Offset = offset
+		case "recurrence":
+			recurrenceNode = value
+		case "missing_date":
+			policy, err := calendar.ParseMissingDatePolicy(value.Value)
+			if err != nil {
+				return fail(value, "%v", err)
+			}
+			rule.MissingDate = &policy
+		default:
+			return fail(key, "unknown key %q", key.Value)
+		}
+	}
+
+	if rule.Title == "" {
+		return fail(node, "title is required")
+	}
+
+	var anchors []*yaml.Node
+	for _, n := range []*yaml.Node{lunarNode, solarNode, termNode, relativeNode} {
+		if n != nil {
+			anchors = append(anchors, n)
+		}
+	}
+	if len(anchors) > 1 {
+		return fail(anchors[1], "an event has only one of lunar, solar, solar_term and relative_to")
+	}
+
+	switch {
+	case lunarNode != nil:
+		date, lastDay, err := calendar.ParseRuleDate(lunarNode.Value)
+		if err != nil {
+			return fail(lunarNode, "%v", err)
+		}
+		rule.Lunar = date
+		rule.LastDay = lastDay
+		rule.Recurring = date.Year == 0
+	case solarNode != nil:
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(solarNode.Value))
+		if err != nil {
+			return fail(solarNode, "invalid solar date %q, expected yyyy-mm-dd", solarNode.Value)
+		}
+		rule.Solar = date
+	case termNode != nil || relativeNode != nil:
+		if recurrenceNode != nil {
+			return fail(recurrenceNode, "an event on a solar term or relative to another event takes its recurrence from its anchor")
+		}
+		rule.Recurring = true
+	default:
+		return fail(node, "a lunar date, a solar date, a solar_term or relative_to is required")
+	}
+
+	if recurrenceNode != nil {
+		switch strings.TrimSpace(recurrenceNode.Value) {
+		case "yearly":
+			rule.Recurring = true
+		case "once":
+			if lunarNode != nil && rule.Lunar.Year == 0 {
+				return fail(recurrenceNode, "an event that happens once needs a year in its lunar date")
+			}
+			rule.Recurring = false
+		default:
+			return fail(recurrenceNode, "unknown recurrence %q, expected yearly or once", recurrenceNode.Value)
+		}
+	}
+
+	return rule, nil
+}
+
+// parseAlarms parses a single alarm or a list of them.
+func parseAlarms(name string, node *yaml.Node) ([]calendar.Alarm, error) {
+	nodes := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		nodes = node.Content
+	}
+
+	var alarms []calendar.Alarm
+	for _, n := range nodes {
+		if n.Kind != yaml.ScalarNode {
+			return nil, &Error{File: name, Line: n.Line, Err: errors.New("alarm must be a single value, e.g. 1d or 2h")}
+		}
+		alarm, err := calendar.ParseAlarm(n.Value)
+		if err != nil {
+			return nil, &Error{File: name, Line: n.Line, Err: err}
+		}
+		alarms = append(alarms, alarm)
+	}
+	return alarms, nil
+}
@@ -0,0 +1,195 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("parses YAML events", func(t *testing.T) {
+		cfg, err := config.Parse("events.yaml", []byte(`
+events:
+  - title: "Giỗ ông: nội"
+    lunar: 30/11
+    missing_date: last
+    category: Giỗ
+    alarms: [1d, 2h]
+    description: Nấu cỗ, mời họ hàng
+  - title: Sinh nhật mẹ
+    solar: 1960-05-12
+    recurrence: yearly
+  - title: Cưới
+    lunar: 15/6n/2025
+    alarms: 0
+`))
+		require.NoError(t, err)
+
+		last := calendar.MissingDateLastDay
+		require.Equal(t, []calendar.Rule{
+			{
+				Title:       "Giỗ ông: nội",
+				Description: "Nấu cỗ, mời họ hàng",
+				Category:    "Giỗ",
+				Alarms:      []calendar.Alarm{{Before: 24 * time.Hour}, {Before: 2 * time.Hour}},
+				Lunar:       calendar.LunarDate{Day: 30, Month: 11},
+				Recurring:   true,
+				MissingDate: &last,
+				Source:      "events.yaml:3",
+			},
+			{
+				Title:     "Sinh nhật mẹ",
+				Solar:     time.Date(1960, time.May, 12, 0, 0, 0, 0, time.UTC),
+				Recurring: true,
+				Source:    "events.yaml:9",
+			},
+			{
+				Title:  "Cưới",
+				Alarms: []calendar.Alarm{{}},
+				Lunar:  calendar.LunarDate{Year: 2025, Day: 15, Month: 6, Leap: true},
+				Source: "events.yaml:12",
+			},
+		}, cfg.Events)
+	})
+
+	t.Run("parses JSON events", func(t *testing.T) {
+		cfg, err := config.Parse("events.json", []byte(`{
+  "events": [
+    {"title": "Rằm", "lunar": "15/1", "alarms": ["1d"]}
+  ]
+}`))
+		require.NoError(t, err)
+
+		require.Len(t, cfg.Events, 1)
+		require.Equal(t, "Rằm", cfg.Events[0].Title)
+		require.Equal(t, calendar.LunarDate{Day: 15, Month: 1}, cfg.Events[0].Lunar)
+		require.True(t, cfg.Events[0].Recurring)
+		require.Equal(t, "events.json:3", cfg.Events[0].Source)
+	})
+
+	t.Run("parses category alarms", func(t *testing.T) {
+		cfg, err := config.Parse("events.yaml", []byte(`
+alarms:
+  Giỗ: [3d@08:00, morning]
+  "*": 1d
+`))
+		require.NoError(t, err)
+
+		require.Equal(t, map[string][]calendar.Alarm{
+			"Giỗ": {{Before: 64 * time.Hour}, {Before: -8 * time.Hour}},
+			"*":   {{Before: 24 * time.Hour}},
+		}, cfg.Alarms)
+	})
+
+	t.Run("parses anchored events", func(t *testing.T) {
+		cfg, err := config.Parse("events.yaml", []byte(`
+events:
+  - title: Giao Thừa
+    lunar: last/12
+  - id: tat-nien
+    title: Tất Niên
+    relative_to: Giao Thừa
+    offset: -1
+  - title: Tảo mộ
+    solar_term: Thanh Minh
+    offset: 2d
+    days: 3
+`))
+		require.NoError(t, err)
+
+		require.Equal(t, []calendar.Rule{
+			{Title: "Giao Thừa", Lunar: calendar.LunarDate{Month: 12}, LastDay: true, Recurring: true, Source: "events.yaml:3"},
+			{ID: "tat-nien", Title: "Tất Niên", RelativeTo: "Giao Thừa", Offset: -1, Recurring: true, Source: "events.yaml:5"},
+			{Title: "Tảo mộ", SolarTerm: "Thanh Minh", Offset: 2, Days: 3, Recurring: true, Source: "events.yaml:9"},
+		}, cfg.Events)
+	})
+
+	t.Run("reports the line of invalid values", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			content  string
+			expected string
+		}{
+			{"unknown top level key", "event: []", "events.yaml:1: unknown key \"event\""},
+			{"events not a list", "events: {}", "events.yaml:1: events must be a list"},
+			{"missing title", "events:\n  - lunar: 1/1", "events.yaml:2: title is required"},
+			{"missing date", "events:\n  - title: X", "events.yaml:2: a lunar date, a solar date, a solar_term or relative_to is required"},
+			{"invalid lunar date", "events:\n  - title: X\n    lunar: 31/1", `events.yaml:3: invalid date "31/1": column 1 near "31": lunar months have at most 30 days`},
+			{"invalid solar date", "events:\n  - title: X\n    solar: 12/05/1960", "events.yaml:3: invalid solar date \"12/05/1960\", expected yyyy-mm-dd"},
+			{"both dates", "events:\n  - title: X\n    lunar: 1/1\n    solar: 2026-01-01", "events.yaml:4: an event has only one of lunar, solar, solar_term and relative_to"},
+			{"invalid offset", "events:\n  - title: X\n    lunar: 1/1\n    offset: soon", `events.yaml:4: invalid offset "soon", expected a number of days such as -1 or 49`},
+			{"invalid days", "events:\n  - title: X\n    lunar: 1/1\n    days: 0", `events.yaml:4: invalid days "0", expected the number of days the event lasts, e.g. 5`},
+			{"alarms not a mapping", "alarms: [1d]", "events.yaml:1: alarms must map categories to alarms"},
+			{"unknown key", "events:\n  - title: X\n    lunar: 1/1\n    colour: red", "events.yaml:4: unknown key \"colour\""},
+			{"invalid alarm", "events:\n  - title: X\n    lunar: 1/1\n    alarms:\n      - 1d\n      - soon", "events.yaml:6: invalid alarm: soon, expected e.g. 1d, 2h, 1d12h, 3d@08:00 or morning"},
+			{"invalid recurrence", "events:\n  - title: X\n    lunar: 1/1\n    recurrence: monthly", "events.yaml:4: unknown recurrence \"monthly\", expected yearly or once"},
+			{"once without year", "events:\n  - title: X\n    lunar: 1/1\n    recurrence: once", "events.yaml:4: an event that happens once needs a year in its lunar date"},
+			{"invalid policy", "events:\n  - title: X\n    lunar: 30/1\n    missing_date: later", "events.yaml:4: unknown missing date policy \"later\", expected one of skip, last, next, error"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := config.Parse("events.yaml", []byte(tc.content))
+
+				require.EqualError(t, err, tc.expected)
+				var configErr *config.Error
+				require.ErrorAs(t, err, &configErr)
+			})
+		}
+	})
+
+	t.Run("reports YAML syntax errors", func(t *testing.T) {
+		_, err := config.Parse("events.yaml", []byte("events:\n  - title: [X"))
+
+		require.ErrorContains(t, err, "events.yaml")
+	})
+
+	t.Run("empty file has no events", func(t *testing.T) {
+		cfg, err := config.Parse("events.yaml", nil)
+
+		require.NoError(t, err)
+		require.Empty(t, cfg.Events)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("events:\n  - title: Rằm\n    lunar: 15/1\n"), 0644))
+
+	cfg, err := config.Load(path)
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Events, 1)
+	require.Equal(t, path+":2", cfg.Events[0].Source)
+}
+
+func TestMarshal(t *testing.T) {
+	last := calendar.MissingDateLastDay
+	cfg := config.Config{
+		Events: []calendar.Rule{
+			{Title: "Giỗ ông: nội", Lunar: calendar.LunarDate{Day: 30, Month: 11}, Recurring: true, MissingDate: &last, Category: "Giỗ", Alarms: []calendar.Alarm{{Before: 24 * time.Hour}}, Description: "Nấu cỗ, mời họ hàng"},
+			{Title: "Sinh nhật mẹ", Solar: time.Date(1960, time.May, 12, 0, 0, 0, 0, time.UTC), Recurring: true},
+			{Title: "Cúng 49 ngày", Lunar: calendar.LunarDate{Year: 2025, Day: 10, Month: 3}, Offset: 48},
+			{Title: "Khai trương", Lunar: calendar.LunarDate{Year: 2020, Day: 8, Month: 1}, Recurring: true},
+			{ID: "tat-nien", Title: "Tất Niên", RelativeTo: "giao-thua", Offset: -1, Recurring: true},
+			{Title: "Tảo mộ", SolarTerm: "Thanh Minh", Days: 3, Recurring: true},
+			{Title: "Giao Thừa", Lunar: calendar.LunarDate{Month: 12}, LastDay: true, Recurring: true},
+		},
+		Alarms: map[string][]calendar.Alarm{
+			"Giỗ": {{Before: 64 * time.Hour}, {Before: -8 * time.Hour}},
+		},
+	}
+
+	data, err := config.Marshal(cfg)
+	require.NoError(t, err)
+
+	parsed, err := config.Parse("events.yaml", data)
+	require.NoError(t, err)
+	for i := range parsed.Events {
+		parsed.Events[i].Source = ""
+	}
+	require.Equal(t, cfg, *parsed)
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/holidays"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadHolidayOverrides reads a YAML or JSON file of the days off announced
+// by the government, replacing the computed ones:
+//
+//	overrides:
+//	  - id: tet
+//	    start: 2026-02-14
+//	    end: 2026-02-22
+//	  - id: national
+//	    start: 2025-08-30
+//	    end: 2025-09-02
+func LoadHolidayOverrides(path string) ([]holidays.Override, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHolidayOverrides(path, data)
+}
+
+// ParseHolidayOverrides parses the content of a holiday override file. name
+// is used in error messages.
+func ParseHolidayOverrides(name string, data []byte) ([]holidays.Override, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, &Error{File: name, Line: root.Line, Err: errors.New("expected a mapping with an overrides list")}
+	}
+	var overrides []holidays.Override
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value != "overrides" {
+			return nil, &Error{File: name, Line: key.Line, Err: fmt.Errorf("unknown key %q", key.Value)}
+		}
+		if value.Kind != yaml.SequenceNode {
+			return nil, &Error{File: name, Line: value.Line, Err: errors.New("overrides must be a list")}
+		}
+		for _, node := range value.Content {
+			o, err := parseOverride(name, node)
+			if err != nil {
+				return nil, err
+			}
+			overrides = append(overrides, o)
+		}
+	}
+	return overrides, nil
+}
+
+func parseOverride(name string, node *yaml.Node) (holidays.Override, error) {
+	fail := func(n *yaml.Node, format string, args ...any) (holidays.Override, error) {
+		return holidays.Override{}, &Error{File: name, Line: n.Line, Err: fmt.Errorf(format, args...)}
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return fail(node, "override must be a mapping")
+	}
+
+	var o holidays.Override
+	var startNode, endNode *yaml.Node
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			return fail(value, "%s must be a single value", key.Value)
+		}
+		switch key.Value {
+		case "id":
+			o.ID = strings.TrimSpace(value.Value)
+		case "name":
+			o.Name = strings.TrimSpace(value.Value)
+		case "start", "end":
+			date, err := time.Parse(time.DateOnly, strings.TrimSpace(value.Value))
+			if err != nil {
+				return fail(value, "invalid date %q, expected yyyy-mm-dd", value.Value)
+			}
+			if key.Value == "start" {
+				o.Start, startNode = date, value
+			} else {
+				o.End, endNode = date, value
+			}
+		default:
+			return fail(key, "unknown key %q", key.Value)
+		}
+	}
+
+	switch {
+	case o.ID == "":
+		return fail(node, "id is required")
+	case startNode == nil:
+		return fail(node, "start is required")
+	case endNode == nil:
+		o.End = o.Start
+	case o.End.Before(o.Start):
+		return fail(endNode, "end is before start")
+	}
+	return o, nil
+}
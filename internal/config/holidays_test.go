@@ -0,0 +1,59 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/config"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/holidays"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHolidayOverrides(t *testing.T) {
+	t.Run("parses overrides", func(t *testing.T) {
+		overrides, err := config.ParseHolidayOverrides("holidays.yaml", []byte(`
+overrides:
+  - id: tet
+    start: 2025-01-25
+    end: 2025-02-02
+  - id: anniversary
+    name: Kỷ niệm 80 năm Quốc khánh
+    start: 2025-09-01
+`))
+		require.NoError(t, err)
+
+		require.Equal(t, []holidays.Override{
+			{
+				ID:    "tet",
+				Start: time.Date(2025, time.January, 25, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2025, time.February, 2, 0, 0, 0, 0, time.UTC),
+			},
+			{
+				ID:    "anniversary",
+				Name:  "Kỷ niệm 80 năm Quốc khánh",
+				Start: time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC),
+			},
+		}, overrides)
+	})
+
+	t.Run("reports the line of invalid values", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			content  string
+			expected string
+		}{
+			{"unknown top level key", "override: []", "holidays.yaml:1: unknown key \"override\""},
+			{"missing id", "overrides:\n  - start: 2025-01-01", "holidays.yaml:2: id is required"},
+			{"missing start", "overrides:\n  - id: tet", "holidays.yaml:2: start is required"},
+			{"invalid date", "overrides:\n  - id: tet\n    start: 25/01/2025", "holidays.yaml:3: invalid date \"25/01/2025\", expected yyyy-mm-dd"},
+			{"end before start", "overrides:\n  - id: tet\n    start: 2025-01-25\n    end: 2025-01-20", "holidays.yaml:4: end is before start"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := config.ParseHolidayOverrides("holidays.yaml", []byte(tc.content))
+
+				require.EqualError(t, err, tc.expected)
+			})
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"gopkg.in/yaml.v3"
+)
+
+type fileYAML struct {
+	Events []eventYAML         `yaml:"events,omitempty"`
+	Alarms map[string][]string `yaml:"alarms,omitempty"`
+}
+
+type eventYAML struct {
+	ID          string   `yaml:"id,omitempty"`
+	Title       string   `yaml:"title"`
+	Lunar       string   `yaml:"lunar,omitempty"`
+	Solar       string   `yaml:"solar,omitempty"`
+	SolarTerm   string   `yaml:"solar_term,omitempty"`
+	RelativeTo  string   `yaml:"relative_to,omitempty"`
+	Offset      int      `yaml:"offset,omitempty"`
+	Days        int      `yaml:"days,omitempty"`
+	Recurrence  string   `yaml:"recurrence,omitempty"`
+	MissingDate string   `yaml:"missing_date,omitempty"`
+	Category    string   `yaml:"category,omitempty"`
+	Alarms      []string `yaml:"alarms,flow,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+}
+
+// Marshal writes the config as YAML that Parse reads back.
+func Marshal(cfg Config) ([]byte, error) {
+	var file fileYAML
+	for _, r := range cfg.Events {
+		e := eventYAML{
+			ID:          r.ID,
+			Title:       r.Title,
+			SolarTerm:   r.SolarTerm,
+			RelativeTo:  r.RelativeTo,
+			Offset:      r.Offset,
+			Days:        r.Days,
+			Category:    r.Category,
+			Alarms:      formatAlarms(r.Alarms),
+			Description: r.Description,
+		}
+		switch {
+		case !r.Solar.IsZero():
+			e.Solar = r.Solar.Format(time.DateOnly)
+			if r.Recurring {
+				e.Recurrence = "yearly"
+			}
+		case r.SolarTerm == "" && r.RelativeTo == "":
+			e.Lunar = calendar.FormatRuleDate(r.Lunar, r.LastDay)
+			if r.Recurring && r.Lunar.Year != 0 {
+				e.Recurrence = "yearly"
+			}
+		}
+		if r.MissingDate != nil {
+			e.MissingDate = r.MissingDate.String()
+		}
+		file.Events = append(file.Events, e)
+	}
+
+	if len(cfg.Alarms) > 0 {
+		file.Alarms = make(map[string][]string, len(cfg.Alarms))
+		for category, alarms := range cfg.Alarms {
+			file.Alarms[category] = formatAlarms(alarms)
+		}
+	}
+	return yaml.Marshal(file)
+}
+
+func formatAlarms(alarms []calendar.Alarm) []string {
+	var s []string
+	for _, a := range alarms {
+		s = append(s, a.String())
+	}
+	return s
+}
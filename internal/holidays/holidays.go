@@ -0,0 +1,191 @@
+// Package holidays computes the official public holidays of Vietnam and the
+// days off they give, following article 112 of the Labour Code 2019.
+//
+// When a holiday falls on a weekend, the days off are carried over to the
+// following working days (nghỉ bù). The government often announces a
+// different schedule, swapping days to make longer breaks; Override replaces
+// the computed days of a holiday with the announced ones.
+package holidays
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
+)
+
+// Holiday IDs identify the public holidays, e.g. in overrides.
+const (
+	NewYear       = "new-year"
+	Tet           = "tet"
+	HungKings     = "hung-kings"
+	Reunification = "reunification"
+	Labour        = "labour"
+	National      = "national"
+)
+
+// Holiday is a range of consecutive days off.
+type Holiday struct {
+	ID    string
+	Name  string
+	Start time.Time
+	// End is the last day off, inclusive.
+	End time.Time
+	// Substitute marks nghỉ bù, days off in lieu of holidays that fell on a
+	// weekend.
+	Substitute bool
+}
+
+// Days returns the number of days off.
+func (h Holiday) Days() int {
+	return int(h.End.Sub(h.Start).Hours()/24+0.5) + 1
+}
+
+// Override replaces the days off of the holiday with the ID in the year of
+// Start, as announced by the government. Name is only needed for a holiday
+// that is not in the Labour Code, such as an extra day off for an
+// anniversary.
+type Override struct {
+	ID    string
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+type rule struct {
+	id   string
+	name string
+	// start returns the first day off in the Gregorian year
+	start func(year int) time.Time
+	days  int
+}
+
+var rules = []rule{
+	{id: NewYear, name: "Tết Dương lịch", days: 1, start: solar(time.January, 1)},
+	{id: Tet, name: "Tết Nguyên Đán", days: 5, start: func(year int) time.Time {
+		// The break starts on the last day of the lunar year
+		return lunar.FindLunarDate(year, lunar.Tet, lunar.WithRange(lunar.TetRange)).AddDate(0, 0, -1)
+	}},
+	{id: HungKings, name: "Giỗ Tổ Hùng Vương", days: 1, start: func(year int) time.Time {
+		return lunar.FindLunarDate(year, lunar.HungKingCommemoration)
+	}},
+	{id: Reunification, name: "Ngày Giải phóng miền Nam", days: 1, start: solar(time.April, 30)},
+	{id: Labour, name: "Ngày Quốc tế Lao động", days: 1, start: solar(time.May, 1)},
+	{id: National, name: "Quốc khánh", days: 2, start: func(year int) time.Time {
+		// 2 September and the day before or after it, whichever joins the
+		// weekend
+		day := solar(time.September, 2)(year)
+		if day.Weekday() == time.Tuesday {
+			return day.AddDate(0, 0, -1)
+		}
+		return day
+	}},
+}
+
+// IDs lists the IDs of the public holidays.
+var IDs = func() []string {
+	ids := make([]string, len(rules))
+	for i, r := range rules {
+		ids[i] = r.id
+	}
+	return ids
+}()
+
+func solar(month time.Month, day int) func(year int) time.Time {
+	return func(year int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, location())
+	}
+}
+
+func location() *time.Location {
+	loc, _ := lunar.LoadLocation("Asia/Hanoi")
+	return loc
+}
+
+// ForYear returns the days off of the Gregorian year in chronological order,
+// with overrides applied. Holidays replaced by an override get no nghỉ bù,
+// as the announced schedule already accounts for weekends.
+func ForYear(year int, overrides ...Override) ([]Holiday, error) {
+	var holidays []Holiday
+	overridden := make(map[string]bool)
+	for _, o := range overrides {
+		if o.Start.Year() != year {
+			continue
+		}
+		if o.End.Before(o.Start) {
+			return nil, fmt.Errorf("override of %s ends before it starts", o.ID)
+		}
+		name := o.Name
+		if name == "" {
+			i := slices.IndexFunc(rules, func(r rule) bool { return r.id == o.ID })
+			if i < 0 {
+				return nil, fmt.Errorf("override of unknown holiday %q needs a name", o.ID)
+			}
+			name = rules[i].name
+		}
+		overridden[o.ID] = true
+		holidays = append(holidays, Holiday{ID: o.ID, Name: name, Start: date(o.Start), End: date(o.End)})
+	}
+
+	var computed []Holiday
+	for _, r := range rules {
+		if overridden[r.id] {
+			continue
+		}
+		start := date(r.start(year))
+		computed = append(computed, Holiday{ID: r.id, Name: r.name, Start: start, End: start.AddDate(0, 0, r.days-1)})
+	}
+
+	off := make(map[time.Time]bool)
+	for _, h := range append(slices.Clip(holidays), computed...) {
+		for d := h.Start; !d.After(h.End); d = d.AddDate(0, 0, 1) {
+			off[d] = true
+		}
+	}
+	for _, h := range computed {
+		holidays = append(holidays, h)
+		holidays = append(holidays, substitutes(h, off)...)
+	}
+
+	slices.SortStableFunc(holidays, func(a, b Holiday) int {
+		return a.Start.Compare(b.Start)
+	})
+	return holidays, nil
+}
+
+// substitutes returns the nghỉ bù of the holiday: one working day for each
+// of its days on a weekend, taken right after it. off holds the days already
+// off and is updated.
+func substitutes(h Holiday, off map[time.Time]bool) []Holiday {
+	owed := 0
+	for d := h.Start; !d.After(h.End); d = d.AddDate(0, 0, 1) {
+		if weekend(d) {
+			owed++
+		}
+	}
+
+	var days []Holiday
+	for d := h.End.AddDate(0, 0, 1); owed > 0; d = d.AddDate(0, 0, 1) {
+		if weekend(d) || off[d] {
+			continue
+		}
+		off[d] = true
+		owed--
+		if n := len(days); n > 0 && days[n-1].End.AddDate(0, 0, 1).Equal(d) {
+			days[n-1].End = d
+			continue
+		}
+		days = append(days, Holiday{ID: h.ID, Name: "Nghỉ bù " + h.Name, Start: d, End: d, Substitute: true})
+	}
+	return days
+}
+
+func weekend(d time.Time) bool {
+	return d.Weekday() == time.Saturday || d.Weekday() == time.Sunday
+}
+
+// date drops the time of day of t, keeping its calendar date.
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location())
+}
@@ -0,0 +1,104 @@
+package holidays_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/holidays"
+	"github.com/stretchr/testify/require"
+)
+
+func findHoliday(days []holidays.Holiday, id string, substitute bool) *holidays.Holiday {
+	for i := range days {
+		if days[i].ID == id && days[i].Substitute == substitute {
+			return &days[i]
+		}
+	}
+	return nil
+}
+
+func TestForYear(t *testing.T) {
+	t.Run("mixes solar and lunar holidays", func(t *testing.T) {
+		days, err := holidays.ForYear(2026)
+
+		require.NoError(t, err)
+		require.Equal(t, holidays.NewYear, days[0].ID)
+		tet := findHoliday(days, holidays.Tet, false)
+		require.NotNil(t, tet)
+		// Tết 2026 is on 17 February, the break starts the day before
+		require.Equal(t, "2026-02-16", tet.Start.Format(time.DateOnly))
+		require.Equal(t, "2026-02-20", tet.End.Format(time.DateOnly))
+		require.Equal(t, 5, tet.Days())
+		national := findHoliday(days, holidays.National, false)
+		require.Equal(t, "2026-09-02", national.Start.Format(time.DateOnly))
+		require.Equal(t, 2, national.Days())
+	})
+
+	t.Run("holiday on a weekend gives nghỉ bù on the next working day", func(t *testing.T) {
+		days, err := holidays.ForYear(2026)
+
+		require.NoError(t, err)
+		// Giỗ Tổ Hùng Vương 2026 is on Sunday 26 April
+		sub := findHoliday(days, holidays.HungKings, true)
+		require.NotNil(t, sub)
+		require.Equal(t, "Nghỉ bù Giỗ Tổ Hùng Vương", sub.Name)
+		require.Equal(t, "2026-04-27", sub.Start.Format(time.DateOnly))
+		require.Equal(t, 1, sub.Days())
+	})
+
+	t.Run("nghỉ bù skips weekends and other days off", func(t *testing.T) {
+		days, err := holidays.ForYear(2027)
+
+		require.NoError(t, err)
+		// Tết 2027 runs from Friday 5 to Tuesday 9 February
+		sub := findHoliday(days, holidays.Tet, true)
+		require.NotNil(t, sub)
+		require.Equal(t, "2027-02-10", sub.Start.Format(time.DateOnly))
+		require.Equal(t, "2027-02-11", sub.End.Format(time.DateOnly))
+	})
+
+	t.Run("Quốc khánh on a Tuesday starts on the Monday", func(t *testing.T) {
+		days, err := holidays.ForYear(2025)
+
+		require.NoError(t, err)
+		national := findHoliday(days, holidays.National, false)
+		require.Equal(t, "2025-09-01", national.Start.Format(time.DateOnly))
+		require.Equal(t, "2025-09-02", national.End.Format(time.DateOnly))
+	})
+
+	t.Run("override replaces the computed days off", func(t *testing.T) {
+		days, err := holidays.ForYear(2025, holidays.Override{
+			ID:    holidays.Tet,
+			Start: time.Date(2025, time.January, 25, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2025, time.February, 2, 0, 0, 0, 0, time.UTC),
+		})
+
+		require.NoError(t, err)
+		tet := findHoliday(days, holidays.Tet, false)
+		require.Equal(t, "Tết Nguyên Đán", tet.Name)
+		require.Equal(t, 9, tet.Days())
+		require.Nil(t, findHoliday(days, holidays.Tet, true))
+	})
+
+	t.Run("override of another year is ignored", func(t *testing.T) {
+		days, err := holidays.ForYear(2026, holidays.Override{
+			ID:    "extra",
+			Name:  "Nghỉ thêm",
+			Start: time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC),
+		})
+
+		require.NoError(t, err)
+		require.Nil(t, findHoliday(days, "extra", false))
+	})
+
+	t.Run("override of an unknown holiday needs a name", func(t *testing.T) {
+		_, err := holidays.ForYear(2025, holidays.Override{
+			ID:    "extra",
+			Start: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+		})
+
+		require.EqualError(t, err, `override of unknown holiday "extra" needs a name`)
+	})
+}
@@ -0,0 +1,101 @@
+package ics
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the longest content line RFC 5545 allows, excluding the
+// line break.
+const maxLineOctets = 75
+
+// contentWriter writes content lines, keeping the first error so that a
+// calendar can be written without checking every line.
+type contentWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *contentWriter) write(s string) {
+	if w.err == nil {
+		var n int
+		n, w.err = io.WriteString(w.w, s)
+		w.n += int64(n)
+	}
+}
+
+// writeLine writes a content line, folding it into lines of at most 75
+// octets. Each continuation line starts with a space, and a fold never
+// splits a UTF-8 character.
+func (w *contentWriter) writeLine(line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.write(line[:cut])
+		w.write("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	w.write(line)
+	w.write("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escapeText escapes a TEXT value: backslashes, semicolons, commas and line
+// breaks.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// unescapeText reverses escapeText. A backslash before any other character
+// is dropped, as some writers escape more than they need to.
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if s[i] == 'n' || s[i] == 'N' {
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// splitList splits a list of TEXT values at the commas that are not
+// escaped.
+func splitList(s string) []string {
+	var values []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(values, s[start:])
+}
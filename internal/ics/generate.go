@@ -1,46 +1,252 @@
 package ics
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"iter"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
 )
 
-func Generate(events []calendar.Event) string {
+type Option func(*config)
+
+type config struct {
+	canChi      bool
+	timestamp   time.Time
+	name        string
+	transparent bool
+}
+
+// WithTimestamp sets the DTSTAMP of every event, by default the Unix epoch
+// so that the same events always give the same file. Build tools can pass
+// the time of SOURCE_DATE_EPOCH.
+func WithTimestamp(t time.Time) Option {
+	return func(c *config) {
+		c.timestamp = t
+	}
+}
+
+// WithCalendarName sets the name calendar apps show for the calendar, by
+// default "Vietnamese Lunar Calendar".
+func WithCalendarName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// WithTransparency marks the events as transparent, so that calendar apps
+// do not show their days as busy.
+func WithTransparency() Option {
+	return func(c *config) {
+		c.transparent = true
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{timestamp: time.Unix(0, 0), name: "Vietnamese Lunar Calendar"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithCanChi adds the Can Chi name of the lunar year to each event's summary
+// and the full Can Chi date to its description.
+func WithCanChi() Option {
+	return func(c *config) {
+		c.canChi = true
+	}
+}
+
+// Generate returns an ICS calendar of the events. The output only depends
+// on the events and options, so regenerating an unchanged calendar gives the
+// same bytes.
+func Generate(events []calendar.Event, opts ...Option) string {
 	buf := &strings.Builder{}
+	// A strings.Builder does not fail
+	_ = NewEncoder(buf, opts...).Encode(slices.Values(events))
+	return buf.String()
+}
+
+// Encoder writes ICS calendars to a stream, one event at a time, so that a
+// long calendar never has to be held in memory.
+type Encoder struct {
+	w     io.Writer
+	cfg   *config
+	stamp string
+	uids  map[string]bool
+}
+
+// NewEncoder returns an encoder writing to w with the options.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	cfg := newConfig(opts)
+	return &Encoder{w: w, cfg: cfg, stamp: formatTimestamp(cfg.timestamp)}
+}
+
+// Encode writes a calendar of the events, stopping at the first write
+// error. The VTIMEZONE of the locations of timed events come after the
+// events, once the span they need to cover is known; RFC 5545 does not
+// order the components of a calendar.
+func (enc *Encoder) Encode(events iter.Seq[calendar.Event]) error {
+	buf := bufio.NewWriter(enc.w)
+	w := &contentWriter{w: buf}
+	enc.uids = make(map[string]bool)
+
+	w.writeHeader(enc.cfg.name)
+
+	var zones timezones
+	for e := range events {
+		zones.add(e)
+		enc.writeEvent(w, e)
+		if w.err != nil {
+			return w.err
+		}
+	}
+
+	for _, span := range zones.spans {
+		w.writeTimezone(span)
+	}
+	w.writeLine("END:VCALENDAR")
+
+	if w.err != nil {
+		return w.err
+	}
+	return buf.Flush()
+}
 
-	buf.WriteString("BEGIN:VCALENDAR\r\n")
-	buf.WriteString("VERSION:2.0\r\n")
-	buf.WriteString("PRODID:-//Vietnamese Lunar Calendar//EN\r\n")
-	buf.WriteString("CALSCALE:GREGORIAN\r\n")
-	buf.WriteString("METHOD:PUBLISH\r\n")
-	buf.WriteString("X-WR-CALNAME:Vietnamese Lunar Calendar\r\n")
-	buf.WriteString("X-WR-TIMEZONE:Asia/Hanoi\r\n")
-
-	for _, e := range events {
-		dateStr := e.Date.Format("20060102")
-
-		buf.WriteString("BEGIN:VEVENT\r\n")
-		buf.WriteString(fmt.Sprintf("UID:vnlunar-%s-%s@lunar-calendar\r\n",
-			e.Title, dateStr))
-		buf.WriteString("DTSTAMP:" + time.Now().UTC().Format("20060102T150405Z") + "\r\n")
-		buf.WriteString(fmt.Sprintf("DTSTART;VALUE=DATE:%s\r\n", dateStr))
-		buf.WriteString(fmt.Sprintf("DTEND;VALUE=DATE:%s\r\n", dateStr))
-		summary := e.Title
-		if e.LunarDate.Show {
-			summary = fmt.Sprintf("%s (%d/%d)", e.Title, e.LunarDate.Day, e.LunarDate.Month)
+func (enc *Encoder) writeEvent(w *contentWriter, e calendar.Event) {
+	dateStr := e.Date.Format("20060102")
+
+	w.writeLine("BEGIN:VEVENT")
+	w.writeLine("UID:" + uid(e, enc.uids))
+	w.writeLine("DTSTAMP:" + enc.stamp)
+	switch {
+	case !e.Timed:
+		w.writeLine(fmt.Sprintf("DTSTART;VALUE=DATE:%s", dateStr))
+		// DTEND of an all-day event is exclusive, the day after the last one
+		w.writeLine(fmt.Sprintf("DTEND;VALUE=DATE:%s", e.Date.AddDate(0, 0, max(e.Days, 1)).Format("20060102")))
+	case e.Date.Location() == time.UTC:
+		w.writeLine(fmt.Sprintf("DTSTART:%s", e.Date.Format("20060102T150405Z")))
+	default:
+		w.writeLine(fmt.Sprintf("DTSTART;TZID=%s:%s", e.Date.Location(), e.Date.Format("20060102T150405")))
+	}
+	summary := e.Title
+	if e.LunarDate.Show {
+		summary = fmt.Sprintf("%s (%d/%d)", e.Title, e.LunarDate.Day, e.LunarDate.Month)
+		if e.LunarDate.Leap {
+			summary = fmt.Sprintf("%s (%d/%d nhuận)", e.Title, e.LunarDate.Day, e.LunarDate.Month)
+		}
+	}
+	description := e.Description
+	if enc.cfg.canChi && e.LunarDate.Year != 0 {
+		summary = fmt.Sprintf("%s - %s", summary, lunar.YearCanChi(e.LunarDate.Year))
+		ld := lunar.Date{Day: e.LunarDate.Day, Month: e.LunarDate.Month, Leap: e.LunarDate.Leap}
+		canChi := lunar.SexagenaryOf(e.LunarDate.Year, ld, e.Date).String()
+		if description == "" {
+			description = canChi
+		} else {
+			description = fmt.Sprintf("%s (%s)", description, canChi)
 		}
-		buf.WriteString(fmt.Sprintf("SUMMARY:%s\r\n", summary))
-		if e.Description != "" {
-			buf.WriteString(fmt.Sprintf("DESCRIPTION:%s\r\n", e.Description))
+	}
+	w.writeLine("SUMMARY:" + escapeText(summary))
+	if description != "" {
+		w.writeLine("DESCRIPTION:" + escapeText(description))
+	}
+	if e.Category != "" {
+		w.writeLine("CATEGORIES:" + escapeText(e.Category))
+	}
+	if enc.cfg.transparent {
+		w.writeLine("TRANSP:TRANSPARENT")
+	}
+	w.writeLine("STATUS:CONFIRMED")
+	for _, alarm := range e.Alarms {
+		w.writeLine("BEGIN:VALARM")
+		w.writeLine("ACTION:DISPLAY")
+		w.writeLine("DESCRIPTION:" + escapeText(summary))
+		w.writeLine(fmt.Sprintf("TRIGGER:%s", formatTrigger(alarm.Before)))
+		w.writeLine("END:VALARM")
+	}
+	w.writeLine("END:VEVENT")
+}
+
+// uid returns a UID for the event from a hash of its ID, or its title when
+// it has none, and its start, so that it stays the same across updates of
+// the calendar. taken holds the UIDs already given and is updated.
+func uid(e calendar.Event, taken map[string]bool) string {
+	id := e.ID
+	if id == "" {
+		id = e.Title
+	}
+	key := id + "@" + e.Date.Format("20060102T150405")
+	for n := 2; ; n++ {
+		sum := sha256.Sum256([]byte(key))
+		uid := "vnlunar-" + hex.EncodeToString(sum[:16]) + "@lunar-calendar"
+		if !taken[uid] {
+			taken[uid] = true
+			return uid
 		}
-		buf.WriteString("STATUS:CONFIRMED\r\n")
-		buf.WriteString("END:VEVENT\r\n")
+		// The same event listed twice
+		key = fmt.Sprintf("%s@%s#%d", id, e.Date.Format("20060102T150405"), n)
 	}
+}
 
-	buf.WriteString("END:VCALENDAR\r\n")
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
 
-	return buf.String()
+func (w *contentWriter) writeHeader(name string) {
+	w.writeLine("BEGIN:VCALENDAR")
+	w.writeLine("VERSION:2.0")
+	w.writeLine("PRODID:-//Vietnamese Lunar Calendar//EN")
+	w.writeLine("CALSCALE:GREGORIAN")
+	w.writeLine("METHOD:PUBLISH")
+	w.writeLine("X-WR-CALNAME:" + escapeText(name))
+	w.writeLine("X-WR-TIMEZONE:Asia/Hanoi")
+}
+
+// formatTrigger formats an alarm going off before the start of an event as
+// a negative RFC 5545 duration, e.g. -P1DT12H, or one going off after the
+// start as a positive duration, e.g. PT8H for 08:00 on the day of an all-day
+// event.
+func formatTrigger(before time.Duration) string {
+	trigger := "-P"
+	if before < 0 {
+		trigger, before = "P", -before
+	}
+
+	days := before / (24 * time.Hour)
+	before -= days * 24 * time.Hour
+	hours := before / time.Hour
+	before -= hours * time.Hour
+	minutes := before / time.Minute
+	before -= minutes * time.Minute
+	seconds := before / time.Second
+
+	if days > 0 {
+		trigger += fmt.Sprintf("%dD", days)
+	}
+	if hours > 0 || minutes > 0 || seconds > 0 {
+		trigger += "T"
+		if hours > 0 {
+			trigger += fmt.Sprintf("%dH", hours)
+		}
+		if minutes > 0 {
+			trigger += fmt.Sprintf("%dM", minutes)
+		}
+		if seconds > 0 {
+			trigger += fmt.Sprintf("%dS", seconds)
+		}
+	}
+	if !strings.ContainsAny(trigger, "DHMS") {
+		// Zero, or less than a second, which RFC 5545 cannot express
+		return "PT0S"
+	}
+	return trigger
 }
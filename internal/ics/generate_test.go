@@ -1,6 +1,8 @@
 package ics_test
 
 import (
+	"bytes"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -62,6 +64,21 @@ func TestGenerate(t *testing.T) {
 		require.NotContains(t, result, "(1/2)")
 	})
 
+	t.Run("marks leap month in summary", func(t *testing.T) {
+		events := []calendar.Event{
+			{
+				Title:       "Leap Event",
+				Date:        time.Date(2025, time.August, 8, 0, 0, 0, 0, time.UTC),
+				LunarDate:   calendar.LunarDate{Day: 15, Month: 6, Leap: true, Show: true},
+				Description: "Leap Description",
+			},
+		}
+
+		result := ics.Generate(events)
+
+		require.Contains(t, result, "SUMMARY:Leap Event (15/6 nhuận)")
+	})
+
 	t.Run("includes multiple events", func(t *testing.T) {
 		events := []calendar.Event{
 			{
@@ -100,3 +117,202 @@ func TestGenerate(t *testing.T) {
 		require.Contains(t, result, "UID:vnlunar-")
 	})
 }
+
+func TestGenerate_WithCanChi(t *testing.T) {
+	events := []calendar.Event{
+		{
+			Title:       "Tết Nguyên Đán",
+			Date:        time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC),
+			LunarDate:   calendar.LunarDate{Year: 2026, Day: 1, Month: 1, Show: true},
+			Description: "Vietnamese Lunar New Year",
+		},
+	}
+
+	t.Run("adds year name to summary and full date to description", func(t *testing.T) {
+		result := ics.Generate(events, ics.WithCanChi())
+
+		require.Contains(t, result, "SUMMARY:Tết Nguyên Đán (1/1) - Bính Ngọ")
+		require.Equal(t, []string{"Vietnamese Lunar New Year (ngày Nhâm Tuất, tháng Canh Dần, năm Bính Ngọ)"}, textValues(t, result, "DESCRIPTION"))
+	})
+
+	t.Run("omitted by default", func(t *testing.T) {
+		result := ics.Generate(events)
+
+		require.NotContains(t, result, "Bính Ngọ")
+	})
+}
+
+func TestGenerate_AllDayEvents(t *testing.T) {
+	t.Run("DTEND is the day after a single day event", func(t *testing.T) {
+		result := ics.Generate([]calendar.Event{
+			{Title: "Tết", Date: time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC)},
+		})
+
+		require.Contains(t, result, "DTSTART;VALUE=DATE:20260217\r\nDTEND;VALUE=DATE:20260218\r\n")
+	})
+
+	t.Run("DTEND is the day after the last day of a multi-day event", func(t *testing.T) {
+		result := ics.Generate([]calendar.Event{
+			{Title: "Nghỉ Tết", Date: time.Date(2026, time.February, 27, 0, 0, 0, 0, time.UTC), Days: 5},
+		})
+
+		require.Contains(t, result, "DTSTART;VALUE=DATE:20260227\r\nDTEND;VALUE=DATE:20260304\r\n")
+	})
+}
+
+func TestGenerate_TimedEvents(t *testing.T) {
+	t.Run("writes DTSTART in the event time zone", func(t *testing.T) {
+		loc := time.FixedZone("Asia/Hanoi", 7*60*60)
+		events := []calendar.Event{
+			{
+				Title: "Trăng non",
+				Date:  time.Date(2026, time.February, 17, 19, 1, 30, 0, loc),
+				Timed: true,
+			},
+		}
+
+		result := ics.Generate(events)
+
+		require.Contains(t, result, "DTSTART;TZID=Asia/Hanoi:20260217T190130\r\n")
+		require.NotContains(t, result, "DTEND")
+		require.Contains(t, result, "BEGIN:VTIMEZONE\r\nTZID:Asia/Hanoi\r\nBEGIN:STANDARD\r\nDTSTART:19700101T000000\r\nTZOFFSETFROM:+0700\r\nTZOFFSETTO:+0700\r\n")
+	})
+
+	t.Run("writes UTC times with the Z suffix", func(t *testing.T) {
+		events := []calendar.Event{
+			{
+				Title: "Trăng non",
+				Date:  time.Date(2026, time.February, 17, 12, 1, 30, 0, time.UTC),
+				Timed: true,
+			},
+		}
+
+		result := ics.Generate(events)
+
+		require.Contains(t, result, "DTSTART:20260217T120130Z\r\n")
+		require.NotContains(t, result, "VTIMEZONE")
+	})
+
+	t.Run("describes daylight saving transitions", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+		events := []calendar.Event{
+			{Title: "Winter", Date: time.Date(2026, time.January, 18, 14, 52, 0, 0, loc), Timed: true},
+			{Title: "Summer", Date: time.Date(2026, time.July, 14, 5, 43, 0, 0, loc), Timed: true},
+		}
+
+		result := ics.Generate(events)
+
+		require.Equal(t, 1, strings.Count(result, "BEGIN:VTIMEZONE"))
+		require.Contains(t, result, "DTSTART;TZID=America/New_York:20260118T145200\r\n")
+		require.Contains(t, result, "BEGIN:DAYLIGHT\r\nDTSTART:20260308T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\n")
+		require.NotContains(t, result, "DTSTART:20261101")
+	})
+}
+
+func TestGenerate_CategoriesAndAlarms(t *testing.T) {
+	events := []calendar.Event{
+		{
+			Title:     "Giỗ ông",
+			Date:      time.Date(2026, time.December, 10, 0, 0, 0, 0, time.UTC),
+			LunarDate: calendar.LunarDate{Day: 2, Month: 11, Show: true},
+			Category:  "Giỗ",
+			Alarms: []calendar.Alarm{
+				{Before: 24 * time.Hour},
+				{Before: 36*time.Hour + 30*time.Minute},
+				{},
+				{Before: -8 * time.Hour},
+				// Too short for any part of a duration
+				{Before: time.Millisecond},
+			},
+		},
+	}
+
+	result := ics.Generate(events)
+
+	require.Contains(t, result, "CATEGORIES:Giỗ\r\n")
+	require.Equal(t, 5, strings.Count(result, "BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Giỗ ông (2/11)\r\n"))
+	require.Contains(t, result, "TRIGGER:-P1D\r\n")
+	require.Contains(t, result, "TRIGGER:-P1DT12H30M\r\n")
+	require.Equal(t, 2, strings.Count(result, "TRIGGER:PT0S\r\n"))
+	require.NotContains(t, result, "TRIGGER:-P\r\n")
+	// 08:00 on the day of an all-day event
+	require.Contains(t, result, "TRIGGER:PT8H\r\n")
+}
+
+func TestGenerate_Reproducible(t *testing.T) {
+	generate := func(t *testing.T, custom string, opts ...ics.Option) string {
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi", calendar.WithSolarTerms(), calendar.WithMoonPhases(), calendar.WithFullMoonDays())
+		events, err := gen.Generate(custom)
+		require.NoError(t, err)
+		return ics.Generate(events, opts...)
+	}
+
+	t.Run("same events give the same bytes", func(t *testing.T) {
+		require.Equal(t, generate(t, "10/3:Giỗ ông"), generate(t, "10/3:Giỗ ông"))
+	})
+
+	t.Run("UIDs are unique", func(t *testing.T) {
+		uids := textValues(t, generate(t, "10/3:Giỗ ông,10/3:Giỗ bà,10/3:Giỗ bà"), "UID")
+
+		seen := make(map[string]bool)
+		for _, uid := range uids {
+			require.Regexp(t, `^vnlunar-[0-9a-f]{32}@lunar-calendar$`, uid)
+			require.False(t, seen[uid], "duplicate UID %s", uid)
+			seen[uid] = true
+		}
+	})
+
+	t.Run("UIDs survive title edits", func(t *testing.T) {
+		before := textValues(t, generate(t, "10/3:Giỗ ông"), "UID")
+		after := textValues(t, generate(t, "10/3:Giỗ ông nội"), "UID")
+
+		require.Equal(t, before, after)
+	})
+
+	t.Run("DTSTAMP defaults to the Unix epoch", func(t *testing.T) {
+		require.Equal(t, "19700101T000000Z", textValues(t, generate(t, ""), "DTSTAMP")[0])
+	})
+
+	t.Run("DTSTAMP can be set", func(t *testing.T) {
+		result := generate(t, "", ics.WithTimestamp(time.Unix(1767225600, 0)))
+
+		require.Equal(t, "20260101T000000Z", textValues(t, result, "DTSTAMP")[0])
+	})
+}
+
+type failingWriter struct{ written int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.written+len(p) > 8192 {
+		return 0, errors.New("disk full")
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func TestEncoder(t *testing.T) {
+	gen := calendar.NewGenerator(2026, 3, "Asia/Hanoi", calendar.WithMoonPhases())
+
+	t.Run("streams the same calendar as Generate", func(t *testing.T) {
+		events, err := gen.Generate("")
+		require.NoError(t, err)
+		seq, err := gen.Events("")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, ics.NewEncoder(&buf, ics.WithCanChi()).Encode(seq))
+
+		require.Equal(t, ics.Generate(events, ics.WithCanChi()), buf.String())
+		parseContentLines(t, buf.String())
+	})
+
+	t.Run("stops at the first write error", func(t *testing.T) {
+		seq, err := gen.Events("")
+		require.NoError(t, err)
+
+		err = ics.NewEncoder(&failingWriter{}).Encode(seq)
+
+		require.EqualError(t, err, "disk full")
+	})
+}
@@ -0,0 +1,42 @@
+package ics
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/holidays"
+)
+
+// CategoryHoliday is the category of the days off in GenerateHolidays.
+const CategoryHoliday = "Nghỉ lễ"
+
+// GenerateHolidays returns an ICS calendar with each range of days off as a
+// single transparent all-day event spanning its days, written like the
+// events of Generate.
+func GenerateHolidays(days []holidays.Holiday, opts ...Option) string {
+	opts = append([]Option{WithCalendarName("Lịch nghỉ lễ Việt Nam"), WithTransparency()}, opts...)
+	buf := &strings.Builder{}
+	// A strings.Builder does not fail
+	_ = NewEncoder(buf, opts...).Encode(slices.Values(holidayEvents(days)))
+	return buf.String()
+}
+
+// holidayEvents returns the days off as all-day events, one per range of
+// days, identified by the holiday so that their UIDs are stable.
+func holidayEvents(days []holidays.Holiday) []calendar.Event {
+	events := make([]calendar.Event, len(days))
+	for i, h := range days {
+		events[i] = calendar.Event{
+			ID:    "holiday/" + h.ID,
+			Title: h.Name,
+			Date:  h.Start,
+			Description: fmt.Sprintf("%s - nghỉ %d ngày, từ %s đến %s",
+				h.Name, h.Days(), h.Start.Format("02/01/2006"), h.End.Format("02/01/2006")),
+			Category: CategoryHoliday,
+			Days:     h.Days(),
+		}
+	}
+	return events
+}
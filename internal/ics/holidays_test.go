@@ -0,0 +1,43 @@
+package ics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/holidays"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/ics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateHolidays(t *testing.T) {
+	result := ics.GenerateHolidays([]holidays.Holiday{
+		{
+			ID:    holidays.Tet,
+			Name:  "Tết Nguyên Đán",
+			Start: time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC),
+		},
+	})
+
+	require.Contains(t, result, "X-WR-CALNAME:Lịch nghỉ lễ Việt Nam\r\n")
+	// The UID is derived like the one of any other event
+	require.Regexp(t, `\r\nUID:vnlunar-[0-9a-f]{32}@lunar-calendar\r\n`, result)
+	require.Contains(t, result, "TRANSP:TRANSPARENT\r\n")
+	require.Contains(t, result, "DTSTART;VALUE=DATE:20260216\r\n")
+	// DTEND is exclusive, the day after the last day off
+	require.Contains(t, result, "DTEND;VALUE=DATE:20260221\r\n")
+	require.Equal(t, []string{"Tết Nguyên Đán - nghỉ 5 ngày, từ 16/02/2026 đến 20/02/2026"}, textValues(t, result, "DESCRIPTION"))
+}
+
+func TestGenerateHolidays_UIDs(t *testing.T) {
+	uids := func(name string) []string {
+		return textValues(t, ics.GenerateHolidays([]holidays.Holiday{{
+			ID:    holidays.Tet,
+			Name:  name,
+			Start: time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2026, time.February, 20, 0, 0, 0, 0, time.UTC),
+		}}), "UID")
+	}
+
+	require.Equal(t, uids("Tết Nguyên Đán"), uids("Tết Âm lịch"))
+}
@@ -0,0 +1,212 @@
+package ics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Calendar is a parsed VCALENDAR. Text values are unescaped; properties
+// without a field of their own, X- properties included, are kept in Extra
+// with their value as written.
+type Calendar struct {
+	Version string
+	ProdID  string
+	Scale   string
+	Method  string
+	// Name is X-WR-CALNAME, the name clients show for the calendar.
+	Name      string
+	Extra     []Property
+	Events    []Event
+	Timezones []Timezone
+}
+
+// Event is a VEVENT.
+type Event struct {
+	UID         string
+	Stamp       DateTime
+	Start       DateTime
+	End         DateTime
+	Summary     string
+	Description string
+	Categories  []string
+	Transp      string
+	Status      string
+	Extra       []Property
+	Alarms      []Alarm
+}
+
+// Alarm is a VALARM.
+type Alarm struct {
+	Action      string
+	Description string
+	// Trigger is when the alarm goes off relative to the start of the
+	// event, negative for before it. It is nil for a trigger at an absolute
+	// time or relative to the end, which is kept in Extra.
+	Trigger *time.Duration
+	Extra   []Property
+}
+
+// Timezone is a VTIMEZONE.
+type Timezone struct {
+	TZID        string
+	Observances []Observance
+	Extra       []Property
+}
+
+// Observance is a STANDARD or DAYLIGHT component of a VTIMEZONE.
+type Observance struct {
+	Daylight bool
+	// Start is the local time the observance starts, a floating DateTime.
+	Start DateTime
+	// OffsetFrom and OffsetTo are UTC offsets in seconds.
+	OffsetFrom int
+	OffsetTo   int
+	Name       string
+	Extra      []Property
+}
+
+// DateTime is a DATE or DATE-TIME value.
+type DateTime struct {
+	Time time.Time
+	// Date values have no time of day, e.g. the start of an all-day event.
+	Date bool
+	// Floating times have no timezone and happen at the same local time
+	// everywhere. Time is in UTC.
+	Floating bool
+	// TZID is the timezone of a time written in local time, and Time is in
+	// its location.
+	TZID string
+}
+
+// IsZero reports whether the value is missing, e.g. the DTEND of a timed
+// event.
+func (d DateTime) IsZero() bool {
+	return d.Time.IsZero()
+}
+
+// Property is a content line kept as written, e.g. an X- property.
+type Property struct {
+	Name   string
+	Params []Param
+	// Value is not unescaped, as its type is unknown.
+	Value string
+}
+
+// Param is a property parameter, e.g. VALUE=DATE.
+type Param struct {
+	Name   string
+	Values []string
+}
+
+// WriteTo writes the calendar in the layout Generate uses, so that parsing
+// the output of Generate and writing it back gives the same bytes.
+func (c *Calendar) WriteTo(w io.Writer) (int64, error) {
+	cw := &contentWriter{w: w}
+
+	cw.writeLine("BEGIN:VCALENDAR")
+	cw.writeText("VERSION", c.Version)
+	cw.writeText("PRODID", c.ProdID)
+	cw.writeText("CALSCALE", c.Scale)
+	cw.writeText("METHOD", c.Method)
+	cw.writeText("X-WR-CALNAME", c.Name)
+	cw.writeProperties(c.Extra)
+	for _, e := range c.Events {
+		cw.writeLine("BEGIN:VEVENT")
+		cw.writeText("UID", e.UID)
+		cw.writeDateTime("DTSTAMP", e.Stamp)
+		cw.writeDateTime("DTSTART", e.Start)
+		cw.writeDateTime("DTEND", e.End)
+		cw.writeText("SUMMARY", e.Summary)
+		cw.writeText("DESCRIPTION", e.Description)
+		if len(e.Categories) > 0 {
+			categories := make([]string, len(e.Categories))
+			for i, category := range e.Categories {
+				categories[i] = escapeText(category)
+			}
+			cw.writeLine("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		cw.writeText("TRANSP", e.Transp)
+		cw.writeText("STATUS", e.Status)
+		cw.writeProperties(e.Extra)
+		for _, a := range e.Alarms {
+			cw.writeLine("BEGIN:VALARM")
+			cw.writeText("ACTION", a.Action)
+			cw.writeText("DESCRIPTION", a.Description)
+			if a.Trigger != nil {
+				cw.writeLine("TRIGGER:" + formatTrigger(-*a.Trigger))
+			}
+			cw.writeProperties(a.Extra)
+			cw.writeLine("END:VALARM")
+		}
+		cw.writeLine("END:VEVENT")
+	}
+	for _, tz := range c.Timezones {
+		cw.writeLine("BEGIN:VTIMEZONE")
+		cw.writeText("TZID", tz.TZID)
+		cw.writeProperties(tz.Extra)
+		for _, o := range tz.Observances {
+			component := "STANDARD"
+			if o.Daylight {
+				component = "DAYLIGHT"
+			}
+			cw.writeLine("BEGIN:" + component)
+			cw.writeDateTime("DTSTART", o.Start)
+			cw.writeLine("TZOFFSETFROM:" + formatOffset(o.OffsetFrom))
+			cw.writeLine("TZOFFSETTO:" + formatOffset(o.OffsetTo))
+			cw.writeText("TZNAME", o.Name)
+			cw.writeProperties(o.Extra)
+			cw.writeLine("END:" + component)
+		}
+		cw.writeLine("END:VTIMEZONE")
+	}
+	cw.writeLine("END:VCALENDAR")
+
+	return cw.n, cw.err
+}
+
+// writeText writes a TEXT property, unless it is empty.
+func (w *contentWriter) writeText(name, value string) {
+	if value != "" {
+		w.writeLine(name + ":" + escapeText(value))
+	}
+}
+
+// writeDateTime writes a DATE or DATE-TIME property, unless it is missing.
+func (w *contentWriter) writeDateTime(name string, d DateTime) {
+	switch {
+	case d.IsZero():
+	case d.Date:
+		w.writeLine(name + ";VALUE=DATE:" + d.Time.Format("20060102"))
+	case d.TZID != "":
+		w.writeLine(fmt.Sprintf("%s;TZID=%s:%s", name, quoteParam(d.TZID), d.Time.Format("20060102T150405")))
+	case d.Floating:
+		w.writeLine(name + ":" + d.Time.Format("20060102T150405"))
+	default:
+		w.writeLine(name + ":" + d.Time.UTC().Format("20060102T150405Z"))
+	}
+}
+
+func (w *contentWriter) writeProperties(props []Property) {
+	for _, p := range props {
+		line := p.Name
+		for _, param := range p.Params {
+			values := make([]string, len(param.Values))
+			for i, v := range param.Values {
+				values[i] = quoteParam(v)
+			}
+			line += ";" + param.Name + "=" + strings.Join(values, ",")
+		}
+		w.writeLine(line + ":" + p.Value)
+	}
+}
+
+// quoteParam quotes a parameter value containing characters that would end
+// it.
+func quoteParam(v string) string {
+	if strings.ContainsAny(v, ":;,") {
+		return `"` + v + `"`
+	}
+	return v
+}
@@ -0,0 +1,513 @@
+package ics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
+)
+
+// ParseError is an invalid content line of an ICS file.
+type ParseError struct {
+	// Line is the number of the first physical line of the content line.
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// contentLine is an unfolded content line, name;params:value.
+type contentLine struct {
+	number int
+	name   string
+	params []Param
+	value  string
+}
+
+func (l contentLine) param(name string) string {
+	for _, p := range l.params {
+		if p.Name == name && len(p.Values) > 0 {
+			return p.Values[0]
+		}
+	}
+	return ""
+}
+
+func (l contentLine) property() Property {
+	return Property{Name: l.name, Params: l.params, Value: l.value}
+}
+
+func (l contentLine) fail(format string, args ...any) error {
+	return &ParseError{Line: l.number, Err: fmt.Errorf(format, args...)}
+}
+
+// Parse reads an ICS calendar: the properties of its VEVENT, VALARM and
+// VTIMEZONE components, and the X- and other properties without a field of
+// their own. Other components, such as VTODO, are skipped. Lines may end
+// with LF instead of CRLF.
+func Parse(r io.Reader) (*Calendar, error) {
+	lines, err := readContentLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || lines[0].name != "BEGIN" || lines[0].value != "VCALENDAR" {
+		return nil, &ParseError{Line: 1, Err: errors.New("expected BEGIN:VCALENDAR")}
+	}
+
+	p := &parser{lines: lines[1:]}
+	cal, err := p.calendar()
+	if err != nil {
+		return nil, err
+	}
+	if err := cal.resolveTimezones(); err != nil {
+		return nil, err
+	}
+	return cal, nil
+}
+
+// readContentLines reads and unfolds the content lines.
+func readContentLines(r io.Reader) ([]contentLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+
+	type logical struct {
+		number int
+		text   string
+	}
+	var unfolded []logical
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if number == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			if len(unfolded) == 0 {
+				return nil, &ParseError{Line: number, Err: errors.New("continuation line without a content line")}
+			}
+			unfolded[len(unfolded)-1].text += text[1:]
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		unfolded = append(unfolded, logical{number: number, text: text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	lines := make([]contentLine, len(unfolded))
+	for i, l := range unfolded {
+		line, err := parseContentLine(l.text)
+		if err != nil {
+			return nil, &ParseError{Line: l.number, Err: err}
+		}
+		line.number = l.number
+		lines[i] = line
+	}
+	return lines, nil
+}
+
+// parseContentLine splits a content line into its name, parameters and
+// value. Parameter values may be quoted to contain ":", ";" and ",".
+func parseContentLine(s string) (contentLine, error) {
+	var line contentLine
+	end := strings.IndexAny(s, ";:")
+	if end <= 0 {
+		return line, fmt.Errorf("invalid content line %q, expected name:value", s)
+	}
+	line.name = strings.ToUpper(s[:end])
+
+	for s[end] == ';' {
+		s = s[end+1:]
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return line, fmt.Errorf("invalid parameter in %s, expected name=value", line.name)
+		}
+		param := Param{Name: strings.ToUpper(s[:eq])}
+		i := eq + 1
+		for {
+			var value string
+			if i < len(s) && s[i] == '"' {
+				closing := strings.IndexByte(s[i+1:], '"')
+				if closing < 0 {
+					return line, fmt.Errorf("unterminated quote in parameter %s of %s", param.Name, line.name)
+				}
+				value = s[i+1 : i+1+closing]
+				i += closing + 2
+			} else {
+				n := strings.IndexAny(s[i:], ",;:")
+				if n < 0 {
+					return line, fmt.Errorf("%s has no value", line.name)
+				}
+				value = s[i : i+n]
+				i += n
+			}
+			param.Values = append(param.Values, value)
+			if i >= len(s) {
+				return line, fmt.Errorf("%s has no value", line.name)
+			}
+			if s[i] != ',' {
+				break
+			}
+			i++
+		}
+		line.params = append(line.params, param)
+		end = i
+		if s[end] != ';' && s[end] != ':' {
+			return line, fmt.Errorf("invalid parameter %s of %s", param.Name, line.name)
+		}
+	}
+
+	line.value = s[end+1:]
+	return line, nil
+}
+
+type parser struct {
+	lines []contentLine
+	pos   int
+	last  contentLine
+}
+
+// next returns the next content line, failing at the end of the file.
+func (p *parser) next() (contentLine, error) {
+	if p.pos == len(p.lines) {
+		return contentLine{}, &ParseError{Line: p.last.number, Err: errors.New("unexpected end of file, expected END:VCALENDAR")}
+	}
+	p.last = p.lines[p.pos]
+	p.pos++
+	return p.last, nil
+}
+
+// component calls property for each property of the component whose BEGIN
+// line was just read, and begin for each subcomponent, until its END line.
+func (p *parser) component(name string, property func(contentLine) error, begin func(contentLine) error) error {
+	for {
+		line, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch line.name {
+		case "END":
+			if line.value != name {
+				return line.fail("expected END:%s, got END:%s", name, line.value)
+			}
+			return nil
+		case "BEGIN":
+			if err := begin(line); err != nil {
+				return err
+			}
+		default:
+			if err := property(line); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// skip skips the component whose BEGIN line was just read.
+func (p *parser) skip(begin contentLine) error {
+	return p.component(begin.value, func(contentLine) error { return nil }, p.skip)
+}
+
+func (p *parser) calendar() (*Calendar, error) {
+	cal := &Calendar{}
+	err := p.component("VCALENDAR", func(line contentLine) error {
+		switch line.name {
+		case "VERSION":
+			cal.Version = unescapeText(line.value)
+		case "PRODID":
+			cal.ProdID = unescapeText(line.value)
+		case "CALSCALE":
+			cal.Scale = unescapeText(line.value)
+		case "METHOD":
+			cal.Method = unescapeText(line.value)
+		case "X-WR-CALNAME":
+			cal.Name = unescapeText(line.value)
+		default:
+			cal.Extra = append(cal.Extra, line.property())
+		}
+		return nil
+	}, func(begin contentLine) error {
+		switch begin.value {
+		case "VEVENT":
+			e, err := p.event()
+			cal.Events = append(cal.Events, e)
+			return err
+		case "VTIMEZONE":
+			tz, err := p.timezone()
+			cal.Timezones = append(cal.Timezones, tz)
+			return err
+		}
+		return p.skip(begin)
+	})
+	return cal, err
+}
+
+func (p *parser) event() (Event, error) {
+	var e Event
+	err := p.component("VEVENT", func(line contentLine) error {
+		var err error
+		switch line.name {
+		case "UID":
+			e.UID = unescapeText(line.value)
+		case "DTSTAMP":
+			e.Stamp, err = parseDateTime(line)
+		case "DTSTART":
+			e.Start, err = parseDateTime(line)
+		case "DTEND":
+			e.End, err = parseDateTime(line)
+		case "SUMMARY":
+			e.Summary = unescapeText(line.value)
+		case "DESCRIPTION":
+			e.Description = unescapeText(line.value)
+		case "CATEGORIES":
+			for _, category := range splitList(line.value) {
+				e.Categories = append(e.Categories, unescapeText(category))
+			}
+		case "TRANSP":
+			e.Transp = unescapeText(line.value)
+		case "STATUS":
+			e.Status = unescapeText(line.value)
+		default:
+			e.Extra = append(e.Extra, line.property())
+		}
+		return err
+	}, func(begin contentLine) error {
+		if begin.value != "VALARM" {
+			return p.skip(begin)
+		}
+		a, err := p.alarm()
+		e.Alarms = append(e.Alarms, a)
+		return err
+	})
+	return e, err
+}
+
+func (p *parser) alarm() (Alarm, error) {
+	var a Alarm
+	err := p.component("VALARM", func(line contentLine) error {
+		switch line.name {
+		case "ACTION":
+			a.Action = unescapeText(line.value)
+		case "DESCRIPTION":
+			a.Description = unescapeText(line.value)
+		case "TRIGGER":
+			if len(line.params) > 0 {
+				// RELATED=END or VALUE=DATE-TIME
+				a.Extra = append(a.Extra, line.property())
+				return nil
+			}
+			trigger, err := parseDuration(line.value)
+			if err != nil {
+				return line.fail("%v", err)
+			}
+			a.Trigger = &trigger
+		default:
+			a.Extra = append(a.Extra, line.property())
+		}
+		return nil
+	}, p.skip)
+	return a, err
+}
+
+func (p *parser) timezone() (Timezone, error) {
+	var tz Timezone
+	err := p.component("VTIMEZONE", func(line contentLine) error {
+		if line.name == "TZID" {
+			tz.TZID = unescapeText(line.value)
+			return nil
+		}
+		tz.Extra = append(tz.Extra, line.property())
+		return nil
+	}, func(begin contentLine) error {
+		if begin.value != "STANDARD" && begin.value != "DAYLIGHT" {
+			return p.skip(begin)
+		}
+		o := Observance{Daylight: begin.value == "DAYLIGHT"}
+		err := p.component(begin.value, func(line contentLine) error {
+			var err error
+			switch line.name {
+			case "DTSTART":
+				o.Start, err = parseDateTime(line)
+			case "TZOFFSETFROM":
+				o.OffsetFrom, err = parseOffset(line)
+			case "TZOFFSETTO":
+				o.OffsetTo, err = parseOffset(line)
+			case "TZNAME":
+				o.Name = unescapeText(line.value)
+			default:
+				o.Extra = append(o.Extra, line.property())
+			}
+			return err
+		}, p.skip)
+		tz.Observances = append(tz.Observances, o)
+		return err
+	})
+	return tz, err
+}
+
+// parseDateTime parses a DATE or DATE-TIME value. Times with a TZID are
+// parsed in UTC and moved to their location by resolveTimezones, once the
+// VTIMEZONE components are known.
+func parseDateTime(line contentLine) (DateTime, error) {
+	value := line.value
+	if line.param("VALUE") == "DATE" || (len(value) == 8 && line.param("VALUE") == "") {
+		t, err := time.Parse("20060102", value)
+		if err != nil {
+			return DateTime{}, line.fail("invalid date %q in %s, expected yyyymmdd", value, line.name)
+		}
+		return DateTime{Time: t, Date: true}, nil
+	}
+
+	if utc, ok := strings.CutSuffix(value, "Z"); ok {
+		t, err := time.Parse("20060102T150405", utc)
+		if err != nil {
+			return DateTime{}, line.fail("invalid date-time %q in %s, expected yyyymmddThhmmssZ", value, line.name)
+		}
+		return DateTime{Time: t}, nil
+	}
+	t, err := time.Parse("20060102T150405", value)
+	if err != nil {
+		return DateTime{}, line.fail("invalid date-time %q in %s, expected yyyymmddThhmmss", value, line.name)
+	}
+	tzid := line.param("TZID")
+	return DateTime{Time: t, TZID: tzid, Floating: tzid == ""}, nil
+}
+
+// resolveTimezones moves the local times of the events to their location:
+// the IANA one of the TZID, or else a fixed zone with the latest offset of
+// the VTIMEZONE of the same TZID.
+func (c *Calendar) resolveTimezones() error {
+	locations := make(map[string]*time.Location)
+	location := func(tzid string) (*time.Location, error) {
+		if loc, ok := locations[tzid]; ok {
+			return loc, nil
+		}
+		loc, err := lunar.LoadLocation(tzid)
+		if err != nil {
+			loc = nil
+			for _, tz := range c.Timezones {
+				if tz.TZID == tzid && len(tz.Observances) > 0 {
+					loc = time.FixedZone(tzid, tz.Observances[len(tz.Observances)-1].OffsetTo)
+				}
+			}
+			if loc == nil {
+				return nil, fmt.Errorf("unknown timezone %q", tzid)
+			}
+		}
+		locations[tzid] = loc
+		return loc, nil
+	}
+
+	for i := range c.Events {
+		e := &c.Events[i]
+		for _, d := range []*DateTime{&e.Stamp, &e.Start, &e.End} {
+			if d.TZID == "" || d.IsZero() {
+				continue
+			}
+			loc, err := location(d.TZID)
+			if err != nil {
+				return fmt.Errorf("event %s: %w", e.UID, err)
+			}
+			t := d.Time
+			d.Time = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+		}
+	}
+	return nil
+}
+
+// parseOffset parses a UTC-OFFSET value, e.g. +0700, into seconds.
+func parseOffset(line contentLine) (int, error) {
+	v := line.value
+	invalid := line.fail("invalid UTC offset %q in %s, expected e.g. +0700", v, line.name)
+	if (len(v) != 5 && len(v) != 7) || (v[0] != '+' && v[0] != '-') {
+		return 0, invalid
+	}
+	offset := 0
+	for i, unit := range []int{3600, 60, 1} {
+		if 1+2*i >= len(v) {
+			break
+		}
+		n, err := strconv.Atoi(v[1+2*i : 3+2*i])
+		if err != nil {
+			return 0, invalid
+		}
+		offset += n * unit
+	}
+	if v[0] == '-' {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// parseDuration parses a DURATION value, e.g. -P1DT12H or P1W.
+func parseDuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q, expected e.g. -P1D or PT8H", s)
+	sign := time.Duration(1)
+	rest := s
+	if after, ok := strings.CutPrefix(rest, "-"); ok {
+		sign, rest = -1, after
+	} else {
+		rest = strings.TrimPrefix(rest, "+")
+	}
+	rest, ok := strings.CutPrefix(rest, "P")
+	if !ok || rest == "" {
+		return 0, invalid
+	}
+
+	// Each of P and T needs at least one part after it, and weeks are not
+	// mixed with other units
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	var d time.Duration
+	timed, parts, weeks := false, 0, false
+	for rest != "" {
+		if rest[0] == 'T' {
+			if timed || weeks {
+				return 0, invalid
+			}
+			timed, parts = true, 0
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+			rest = rest[1:]
+			continue
+		}
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return 0, invalid
+		}
+		n, err := strconv.Atoi(rest[:i])
+		unit, ok := units[rest[i]]
+		if err != nil || !ok || weeks {
+			return 0, invalid
+		}
+		if rest[i] == 'W' {
+			if parts > 0 {
+				return 0, invalid
+			}
+			weeks = true
+		}
+		d += time.Duration(n) * unit
+		parts++
+		rest = rest[i+1:]
+	}
+	if parts == 0 {
+		return 0, invalid
+	}
+	return sign * d, nil
+}
@@ -0,0 +1,196 @@
+package ics_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/holidays"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/ics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_RoundTrip(t *testing.T) {
+	roundTrip := func(t *testing.T, content string) *ics.Calendar {
+		t.Helper()
+		cal, err := ics.Parse(strings.NewReader(content))
+		require.NoError(t, err)
+
+		var out strings.Builder
+		n, err := cal.WriteTo(&out)
+		require.NoError(t, err)
+		require.Equal(t, content, out.String())
+		require.Equal(t, int64(len(content)), n)
+		return cal
+	}
+
+	t.Run("generated calendar", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 2, "Asia/Hanoi",
+			calendar.WithSolarTerms(),
+			calendar.WithMoonPhases(),
+			calendar.WithCategoryAlarms("", calendar.Alarm{Before: 24 * time.Hour}, calendar.Alarm{Before: -8 * time.Hour}),
+		)
+		events, err := gen.Generate(`1/1+5:Nghỉ Tết,10/3:"Giỗ ông, bà; nội":` + strings.Repeat("Mâm cỗ ", 20))
+		require.NoError(t, err)
+
+		roundTrip(t, ics.Generate(events, ics.WithCanChi()))
+	})
+
+	t.Run("timed events in other timezones", func(t *testing.T) {
+		ny, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+
+		roundTrip(t, ics.Generate([]calendar.Event{
+			{Title: "UTC", Date: time.Date(2026, time.February, 17, 12, 1, 30, 0, time.UTC), Timed: true},
+			{Title: "New York", Date: time.Date(2026, time.January, 18, 14, 52, 0, 0, ny), Timed: true},
+			{Title: "New York", Date: time.Date(2026, time.July, 10, 1, 37, 0, 0, ny), Timed: true},
+		}))
+	})
+
+	t.Run("holidays", func(t *testing.T) {
+		days, err := holidays.ForYear(2026)
+		require.NoError(t, err)
+
+		roundTrip(t, ics.GenerateHolidays(days, ics.WithTimestamp(time.Unix(1767225600, 0))))
+	})
+}
+
+func TestParse(t *testing.T) {
+	gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithMoonPhases(), calendar.WithoutFestivals(calendar.FestivalIDs...))
+	events, err := gen.Generate("10/3:Giỗ ông")
+	require.NoError(t, err)
+	events[0].Category = "Giỗ, họ nội"
+	events[0].Alarms = []calendar.Alarm{{Before: 36 * time.Hour}}
+
+	cal, err := ics.Parse(strings.NewReader(ics.Generate(events)))
+	require.NoError(t, err)
+
+	require.Equal(t, "2.0", cal.Version)
+	require.Equal(t, "Vietnamese Lunar Calendar", cal.Name)
+	require.Equal(t, []ics.Property{{Name: "X-WR-TIMEZONE", Value: "Asia/Hanoi"}}, cal.Extra)
+	require.Len(t, cal.Events, len(events))
+
+	t.Run("all-day event", func(t *testing.T) {
+		e := cal.Events[0]
+
+		require.Equal(t, "Giỗ ông (10/3)", e.Summary)
+		require.Equal(t, ics.DateTime{Time: time.Date(2026, time.April, 26, 0, 0, 0, 0, time.UTC), Date: true}, e.Start)
+		require.Equal(t, ics.DateTime{Time: time.Date(2026, time.April, 27, 0, 0, 0, 0, time.UTC), Date: true}, e.End)
+		require.Equal(t, []string{"Giỗ, họ nội"}, e.Categories)
+		require.Equal(t, time.Unix(0, 0).UTC(), e.Stamp.Time)
+		require.Len(t, e.Alarms, 1)
+		require.Equal(t, -36*time.Hour, *e.Alarms[0].Trigger)
+	})
+
+	t.Run("timed event", func(t *testing.T) {
+		e := cal.Events[1]
+
+		require.Equal(t, "Asia/Hanoi", e.Start.TZID)
+		require.True(t, e.Start.Time.Equal(events[1].Date))
+		require.True(t, e.End.IsZero())
+		require.Len(t, cal.Timezones, 1)
+		require.Equal(t, ics.Observance{
+			Start:      ics.DateTime{Time: time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC), Floating: true},
+			OffsetFrom: 7 * 3600,
+			OffsetTo:   7 * 3600,
+			Name:       "Asia/Hanoi",
+		}, cal.Timezones[0].Observances[0])
+	})
+}
+
+func TestParse_OtherWriters(t *testing.T) {
+	content := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Example//EN",
+		"X-WR-CALNAME:Gia đình",
+		"BEGIN:VTIMEZONE",
+		"TZID:SE Asia Standard Time",
+		"BEGIN:STANDARD",
+		"DTSTART:16010101T000000",
+		"TZOFFSETFROM:+0700",
+		"TZOFFSETTO:+0700",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:abc@example.com",
+		"DTSTART;TZID=SE Asia Standard Time:20260301T090000",
+		"DTEND;TZID=\"SE Asia Standard Time\":20260301T100000",
+		"SUMMARY:Họp mặt gia đình\\, năm",
+		"\t mới",
+		"RRULE:FREQ=YEARLY",
+		"X-MICROSOFT-CDO-BUSYSTATUS:FREE",
+		"ATTENDEE;ROLE=REQ-PARTICIPANT;CN=\"Nguyễn, Văn A\":mailto:a@example.com",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"TRIGGER;RELATED=END:-PT15M",
+		"END:VALARM",
+		"BEGIN:VALARM",
+		"ACTION:AUDIO",
+		"TRIGGER:-P1W",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"SUMMARY:Skipped",
+		"END:VTODO",
+		"BEGIN:VEVENT",
+		"DTSTART:20260501",
+		"SUMMARY:Floating date",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\n")
+
+	cal, err := ics.Parse(strings.NewReader(content))
+	require.NoError(t, err)
+
+	require.Equal(t, "Gia đình", cal.Name)
+	require.Len(t, cal.Events, 2)
+
+	e := cal.Events[0]
+	require.Equal(t, "Họp mặt gia đình, năm mới", e.Summary)
+	require.Equal(t, "SE Asia Standard Time", e.Start.TZID)
+	require.Equal(t, time.Date(2026, time.March, 1, 2, 0, 0, 0, time.UTC), e.Start.Time.UTC())
+	require.Equal(t, time.Date(2026, time.March, 1, 3, 0, 0, 0, time.UTC), e.End.Time.UTC())
+	require.Equal(t, []ics.Property{
+		{Name: "RRULE", Value: "FREQ=YEARLY"},
+		{Name: "X-MICROSOFT-CDO-BUSYSTATUS", Value: "FREE"},
+		{Name: "ATTENDEE", Params: []ics.Param{{Name: "ROLE", Values: []string{"REQ-PARTICIPANT"}}, {Name: "CN", Values: []string{"Nguyễn, Văn A"}}}, Value: "mailto:a@example.com"},
+	}, e.Extra)
+	require.Nil(t, e.Alarms[0].Trigger)
+	require.Equal(t, []ics.Property{{Name: "TRIGGER", Params: []ics.Param{{Name: "RELATED", Values: []string{"END"}}}, Value: "-PT15M"}}, e.Alarms[0].Extra)
+	require.Equal(t, -7*24*time.Hour, *e.Alarms[1].Trigger)
+
+	require.Equal(t, ics.DateTime{Time: time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC), Date: true}, cal.Events[1].Start)
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"empty", "", "line 1: expected BEGIN:VCALENDAR"},
+		{"not a calendar", "BEGIN:VEVENT\r\nEND:VEVENT\r\n", "line 1: expected BEGIN:VCALENDAR"},
+		{"unterminated", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:X\r\n", "line 3: unexpected end of file, expected END:VCALENDAR"},
+		{"mismatched end", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n", "line 3: expected END:VEVENT, got END:VCALENDAR"},
+		{"no value", "BEGIN:VCALENDAR\r\nSUMMARY\r\n", `line 2: invalid content line "SUMMARY", expected name:value`},
+		{"unterminated quote", "BEGIN:VCALENDAR\r\nX-A;B=\"c:d\r\n", "line 2: unterminated quote in parameter B of X-A"},
+		{"invalid date", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:2026-03-01\r\n", `line 3: invalid date "2026-03-01" in DTSTART, expected yyyymmdd`},
+		{"invalid trigger", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nBEGIN:VALARM\r\nTRIGGER:soon\r\n", `line 4: invalid duration "soon", expected e.g. -P1D or PT8H`},
+		{"invalid trigger PT", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nBEGIN:VALARM\r\nTRIGGER:PT\r\n", `line 4: invalid duration "PT", expected e.g. -P1D or PT8H`},
+		{"invalid trigger -P1DT", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nBEGIN:VALARM\r\nTRIGGER:-P1DT\r\n", `line 4: invalid duration "-P1DT", expected e.g. -P1D or PT8H`},
+		{"invalid trigger PTT1H", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nBEGIN:VALARM\r\nTRIGGER:PTT1H\r\n", `line 4: invalid duration "PTT1H", expected e.g. -P1D or PT8H`},
+		{"invalid trigger P1DT1HT2M", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nBEGIN:VALARM\r\nTRIGGER:P1DT1HT2M\r\n", `line 4: invalid duration "P1DT1HT2M", expected e.g. -P1D or PT8H`},
+		{"invalid trigger P1W2D", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nBEGIN:VALARM\r\nTRIGGER:P1W2D\r\n", `line 4: invalid duration "P1W2D", expected e.g. -P1D or PT8H`},
+		{"invalid trigger P2D1W", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nBEGIN:VALARM\r\nTRIGGER:P2D1W\r\n", `line 4: invalid duration "P2D1W", expected e.g. -P1D or PT8H`},
+		{"invalid trigger P1WT1H", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nBEGIN:VALARM\r\nTRIGGER:P1WT1H\r\n", `line 4: invalid duration "P1WT1H", expected e.g. -P1D or PT8H`},
+		{"unknown timezone", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\nDTSTART;TZID=Nowhere:20260301T090000\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n", `event x: unknown timezone "Nowhere"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ics.Parse(strings.NewReader(tc.content))
+
+			require.EqualError(t, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package ics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+)
+
+// timezoneSpan is the range of timed events using a location.
+type timezoneSpan struct {
+	loc         *time.Location
+	first, last time.Time
+}
+
+// timezones collects, in order of first use, the locations referenced by
+// the DTSTART of timed events. UTC is written with the Z suffix instead.
+type timezones struct {
+	spans  []*timezoneSpan
+	byName map[string]*timezoneSpan
+}
+
+func (z *timezones) add(e calendar.Event) {
+	if !e.Timed || e.Date.Location() == time.UTC {
+		return
+	}
+	name := e.Date.Location().String()
+	span, ok := z.byName[name]
+	if !ok {
+		if z.byName == nil {
+			z.byName = make(map[string]*timezoneSpan)
+		}
+		span = &timezoneSpan{loc: e.Date.Location(), first: e.Date, last: e.Date}
+		z.byName[name] = span
+		z.spans = append(z.spans, span)
+	}
+	if e.Date.Before(span.first) {
+		span.first = e.Date
+	}
+	if e.Date.After(span.last) {
+		span.last = e.Date
+	}
+}
+
+// writeTimezone writes a VTIMEZONE with one observance per UTC offset change
+// of the location between the span's first and last event, which is all a
+// client needs to resolve their DTSTART.
+func (w *contentWriter) writeTimezone(span *timezoneSpan) {
+	w.writeLine("BEGIN:VTIMEZONE")
+	w.writeLine(fmt.Sprintf("TZID:%s", span.loc))
+
+	t := span.first.In(span.loc)
+	for {
+		start, end := t.ZoneBounds()
+		name, offset := t.Zone()
+		from := offset
+		onset := "19700101T000000"
+		if !start.IsZero() {
+			_, from = start.Add(-time.Second).Zone()
+			onset = start.In(time.FixedZone("", from)).Format("20060102T150405")
+		}
+
+		component := "STANDARD"
+		if t.IsDST() {
+			component = "DAYLIGHT"
+		}
+		w.writeLine(fmt.Sprintf("BEGIN:%s", component))
+		w.writeLine(fmt.Sprintf("DTSTART:%s", onset))
+		w.writeLine(fmt.Sprintf("TZOFFSETFROM:%s", formatOffset(from)))
+		w.writeLine(fmt.Sprintf("TZOFFSETTO:%s", formatOffset(offset)))
+		w.writeLine(fmt.Sprintf("TZNAME:%s", name))
+		w.writeLine(fmt.Sprintf("END:%s", component))
+
+		if end.IsZero() || end.After(span.last) {
+			break
+		}
+		t = end.In(span.loc)
+	}
+
+	w.writeLine("END:VTIMEZONE")
+}
+
+// formatOffset formats a UTC offset in seconds as RFC 5545 UTC-OFFSET, e.g.
+// +0700 or +054500 when it has seconds.
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+	if offset%60 != 0 {
+		s += fmt.Sprintf("%02d", offset%60)
+	}
+	return s
+}
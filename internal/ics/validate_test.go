@@ -0,0 +1,170 @@
+package ics_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/holidays"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/ics"
+	"github.com/stretchr/testify/require"
+)
+
+// property is an unfolded content line, name;params:value.
+type property struct {
+	Name   string
+	Params string
+	Value  string
+}
+
+// parseContentLines checks that the output follows the RFC 5545 content line
+// rules and returns its unfolded lines: lines end with CRLF, are at most 75
+// octets of valid UTF-8, folds do not split a character and components are
+// balanced.
+func parseContentLines(t *testing.T, s string) []property {
+	t.Helper()
+
+	require.True(t, strings.HasSuffix(s, "\r\n"), "output must end with CRLF")
+	physical := strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n")
+
+	var unfolded []string
+	for i, line := range physical {
+		require.NotContains(t, line, "\n", "line %d has a bare line break", i+1)
+		require.NotContains(t, line, "\r", "line %d has a bare line break", i+1)
+		require.LessOrEqual(t, len(line), 75, "line %d is longer than 75 octets: %q", i+1, line)
+		require.True(t, utf8.ValidString(line), "line %d splits a character: %q", i+1, line)
+
+		if strings.HasPrefix(line, " ") {
+			require.NotEmpty(t, unfolded, "output starts with a continuation line")
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+
+	var props []property
+	var open []string
+	for _, line := range unfolded {
+		head, value, found := strings.Cut(line, ":")
+		require.True(t, found, "line has no value: %q", line)
+		name, params, _ := strings.Cut(head, ";")
+		require.NotEmpty(t, name, "line has no name: %q", line)
+		require.Equal(t, strings.ToUpper(name), name, "name is not upper case: %q", line)
+
+		switch name {
+		case "BEGIN":
+			open = append(open, value)
+		case "END":
+			require.NotEmpty(t, open, "END:%s without BEGIN", value)
+			require.Equal(t, open[len(open)-1], value, "unbalanced END")
+			open = open[:len(open)-1]
+		}
+		props = append(props, property{Name: name, Params: params, Value: value})
+	}
+	require.Empty(t, open, "components are not closed")
+	require.Equal(t, "VCALENDAR", props[0].Value)
+	return props
+}
+
+// unescapeText reverses the escaping of a TEXT value, failing on characters
+// that must be escaped but are not.
+func unescapeText(t *testing.T, s string) string {
+	t.Helper()
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\':
+			require.Less(t, i+1, len(s), "dangling backslash in %q", s)
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			case '\\', ';', ',':
+				b.WriteByte(s[i])
+			default:
+				require.Failf(t, "invalid escape", "\\%c in %q", s[i], s)
+			}
+		case ';', ',':
+			require.Failf(t, "unescaped character", "%c in %q", c, s)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// textValues returns the unescaped values of the properties with the name.
+func textValues(t *testing.T, s, name string) []string {
+	t.Helper()
+
+	var values []string
+	for _, p := range parseContentLines(t, s) {
+		if p.Name == name {
+			values = append(values, unescapeText(t, p.Value))
+		}
+	}
+	return values
+}
+
+func TestGenerate_RFC5545(t *testing.T) {
+	title := `Giỗ ông, bà; "nội\ngoại"` + "\nCầu siêu"
+	description := strings.Repeat("Lễ cúng tổ tiên vào ngày mười lăm tháng bảy, ", 5) + "cuối\r\ncùng"
+	events := []calendar.Event{
+		{
+			Title:       title,
+			Date:        time.Date(2026, time.August, 27, 0, 0, 0, 0, time.UTC),
+			LunarDate:   calendar.LunarDate{Year: 2026, Day: 15, Month: 7},
+			Description: description,
+			Category:    "Giỗ, họ nội",
+			Alarms:      []calendar.Alarm{{Before: 24 * time.Hour}},
+		},
+		{
+			Title:     strings.Repeat("ệ", 60),
+			Date:      time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+			LunarDate: calendar.LunarDate{Year: 2026, Day: 20, Month: 7},
+		},
+	}
+
+	t.Run("folds and escapes content lines", func(t *testing.T) {
+		result := ics.Generate(events, ics.WithCanChi())
+
+		props := parseContentLines(t, result)
+		require.Equal(t, "VCALENDAR", props[len(props)-1].Value)
+		require.Contains(t, result, "\r\n ", "long lines are folded")
+	})
+
+	t.Run("round trips text values", func(t *testing.T) {
+		result := ics.Generate(events)
+
+		require.Equal(t, []string{title, strings.Repeat("ệ", 60)}, textValues(t, result, "SUMMARY"))
+		require.Equal(t, []string{strings.ReplaceAll(description, "\r\n", "\n"), title}, textValues(t, result, "DESCRIPTION"))
+		require.Equal(t, []string{"Giỗ, họ nội"}, textValues(t, result, "CATEGORIES"))
+	})
+
+	t.Run("folds at character boundaries at every offset", func(t *testing.T) {
+		for n := 1; n <= 80; n++ {
+			title := strings.Repeat("a", n) + strings.Repeat("Đ", 40) + strings.Repeat("😀", 20)
+			result := ics.Generate([]calendar.Event{{Title: title, Date: events[0].Date}})
+
+			require.Equal(t, []string{title}, textValues(t, result, "SUMMARY"))
+		}
+	})
+}
+
+func TestGenerateHolidays_RFC5545(t *testing.T) {
+	result := ics.GenerateHolidays([]holidays.Holiday{
+		{
+			ID:    holidays.National,
+			Name:  "Quốc khánh; nghỉ bù",
+			Start: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2026, time.September, 2, 0, 0, 0, 0, time.UTC),
+		},
+	})
+
+	require.Equal(t, []string{"Quốc khánh; nghỉ bù"}, textValues(t, result, "SUMMARY"))
+	require.Equal(t, []string{"Quốc khánh; nghỉ bù - nghỉ 2 ngày, từ 01/09/2026 đến 02/09/2026"}, textValues(t, result, "DESCRIPTION"))
+}
@@ -0,0 +1,142 @@
+// Package importer converts events on solar dates, such as the birthdays
+// and dates of death relatives keep in their phone calendars, to custom
+// events recurring every year on the lunar date of the original day.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/ics"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
+)
+
+// Event is an event on a solar date.
+type Event struct {
+	Title       string
+	Description string
+	// Date is the original day, e.g. the day of birth; only its calendar
+	// date is used.
+	Date time.Time
+	// Timed events happen at the instant in Date rather than on a whole
+	// day, and are dated on their day in the timezone given to Rules.
+	Timed bool
+}
+
+// ReadICS reads the events of an ICS file. Events at a time in UTC or in a
+// time zone are Timed; all-day events and ones in floating local time are
+// not. Occurrences that override one of a recurring event, with a
+// RECURRENCE-ID, are left out, since only the original date matters.
+func ReadICS(r io.Reader) ([]Event, error) {
+	cal, err := ics.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, e := range cal.Events {
+		if slices.ContainsFunc(e.Extra, func(p ics.Property) bool { return p.Name == "RECURRENCE-ID" }) {
+			continue
+		}
+		if e.Start.IsZero() {
+			return nil, fmt.Errorf("event %q has no DTSTART", e.Summary)
+		}
+		if strings.TrimSpace(e.Summary) == "" {
+			return nil, fmt.Errorf("event on %s has no SUMMARY", e.Start.Time.Format(time.DateOnly))
+		}
+		events = append(events, Event{
+			Title:       strings.TrimSpace(e.Summary),
+			Description: e.Description,
+			Date:        e.Start.Time,
+			Timed:       !e.Start.Date && !e.Start.Floating,
+		})
+	}
+	return events, nil
+}
+
+// ReadCSV reads events from CSV rows of date,title[,description], with the
+// date as yyyy-mm-dd. A first row starting with "date" is a header.
+func ReadCSV(r io.Reader) ([]Event, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var events []Event
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row == 1 {
+			// Spreadsheets often start UTF-8 files with a byte order mark
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+				continue
+			}
+		}
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("row %d: expected date,title[,description]", row)
+		}
+
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date %q, expected yyyy-mm-dd", row, record[0])
+		}
+		title := strings.TrimSpace(record[1])
+		if title == "" {
+			return nil, fmt.Errorf("row %d: title cannot be empty", row)
+		}
+		event := Event{Title: title, Date: date}
+		if len(record) == 3 {
+			event.Description = strings.TrimSpace(record[2])
+		}
+		events = append(events, event)
+	}
+}
+
+// Rules converts the events to rules recurring every year on the lunar date
+// of their original day in the timezone, which Timed events are dated in.
+// Like a giỗ, an event from a leap month recurs in the regular month of the
+// same number, and one on day 30 falls back to day 29 in the years the month
+// is short.
+func Rules(events []Event, timezone string) []calendar.Rule {
+	loc, err := lunar.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	rules := make([]calendar.Rule, len(events))
+	for i, e := range events {
+		if e.Timed {
+			e.Date = e.Date.In(loc)
+		}
+		year, ld := lunar.FromSolar(e.Date, lunar.WithTimezone(timezone))
+
+		description := e.Description
+		if description == "" {
+			original := calendar.FormatRuleDate(calendar.LunarDate{Year: year, Day: ld.Day, Month: ld.Month, Leap: ld.Leap}, false)
+			description = fmt.Sprintf("%s - Ngày %s dương lịch, %s âm lịch", e.Title, e.Date.Format("02/01/2006"), original)
+		}
+		rule := calendar.Rule{
+			Title:       e.Title,
+			Description: description,
+			Lunar:       calendar.LunarDate{Day: ld.Day, Month: ld.Month},
+			Recurring:   true,
+		}
+		if ld.Day == 30 {
+			last := calendar.MissingDateLastDay
+			rule.MissingDate = &last
+		}
+		rules[i] = rule
+	}
+	return rules
+}
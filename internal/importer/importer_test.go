@@ -0,0 +1,114 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/calendar"
+	"github.com/hpcsc/vietnamese-lunar-calendar/internal/importer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadICS(t *testing.T) {
+	events, err := importer.ReadICS(strings.NewReader(strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:mom",
+		"DTSTART;VALUE=DATE:19600512",
+		"RRULE:FREQ=YEARLY",
+		"SUMMARY:Sinh nhật mẹ",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:mom",
+		"RECURRENCE-ID;VALUE=DATE:20260512",
+		"DTSTART;VALUE=DATE:20260513",
+		"SUMMARY:Sinh nhật mẹ (dời)",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:grandpa",
+		"DTSTART;TZID=Asia/Ho_Chi_Minh:20200315T233000",
+		"SUMMARY:Giỗ ông",
+		"DESCRIPTION:Nấu cỗ\\, mời họ hàng",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:dad",
+		"DTSTART:19580301T200000Z",
+		"SUMMARY:Sinh nhật bố",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")))
+
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, "Sinh nhật mẹ", events[0].Title)
+	require.Equal(t, "1960-05-12", events[0].Date.Format(time.DateOnly))
+	require.False(t, events[0].Timed)
+	require.Equal(t, "Giỗ ông", events[1].Title)
+	require.Equal(t, "Nấu cỗ, mời họ hàng", events[1].Description)
+	require.True(t, events[1].Timed)
+	require.True(t, events[2].Timed)
+
+	t.Run("timed events are dated in the timezone", func(t *testing.T) {
+		rules := importer.Rules(events[1:], "Asia/Hanoi")
+
+		// 23:30 in Ho Chi Minh City is the same day in Hanoi
+		require.Equal(t, calendar.LunarDate{Day: 22, Month: 2}, rules[0].Lunar)
+		// 20:00 UTC on 1 March is the morning of 2 March in Hanoi
+		require.Equal(t, "Sinh nhật bố - Ngày 02/03/1958 dương lịch, 13/1/1958 âm lịch", rules[1].Description)
+	})
+}
+
+func TestReadCSV(t *testing.T) {
+	t.Run("reads rows with an optional header", func(t *testing.T) {
+		events, err := importer.ReadCSV(strings.NewReader("\ufeffdate,title,description\n1960-05-12,Sinh nhật mẹ\n2020-03-15,Giỗ ông,\"Nấu cỗ, mời họ hàng\"\n"))
+
+		require.NoError(t, err)
+		require.Equal(t, []importer.Event{
+			{Title: "Sinh nhật mẹ", Date: time.Date(1960, time.May, 12, 0, 0, 0, 0, time.UTC)},
+			{Title: "Giỗ ông", Description: "Nấu cỗ, mời họ hàng", Date: time.Date(2020, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		}, events)
+	})
+
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"invalid date", "12/05/1960,Sinh nhật mẹ\n", `row 1: invalid date "12/05/1960", expected yyyy-mm-dd`},
+		{"missing title", "date,title\n1960-05-12\n", "row 2: expected date,title[,description]"},
+		{"empty title", "1960-05-12, \n", "row 1: title cannot be empty"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := importer.ReadCSV(strings.NewReader(tc.content))
+
+			require.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	rules := importer.Rules([]importer.Event{
+		{Title: "Sinh nhật mẹ", Date: time.Date(1960, time.May, 12, 0, 0, 0, 0, time.UTC)},
+		{Title: "Giỗ bà", Description: "Nấu cỗ", Date: time.Date(2023, time.April, 19, 0, 0, 0, 0, time.UTC)},
+		{Title: "Giỗ ông", Date: time.Date(2024, time.February, 9, 0, 0, 0, 0, time.UTC)},
+	}, "Asia/Hanoi")
+
+	last := calendar.MissingDateLastDay
+	require.Equal(t, []calendar.Rule{
+		{Title: "Sinh nhật mẹ", Description: "Sinh nhật mẹ - Ngày 12/05/1960 dương lịch, 17/4/1960 âm lịch", Lunar: calendar.LunarDate{Day: 17, Month: 4}, Recurring: true},
+		// Leap month 2 of 2023 recurs in month 2
+		{Title: "Giỗ bà", Description: "Nấu cỗ", Lunar: calendar.LunarDate{Day: 29, Month: 2}, Recurring: true},
+		{Title: "Giỗ ông", Description: "Giỗ ông - Ngày 09/02/2024 dương lịch, 30/12/2023 âm lịch", Lunar: calendar.LunarDate{Day: 30, Month: 12}, Recurring: true, MissingDate: &last},
+	}, rules)
+
+	t.Run("recur on the lunar date", func(t *testing.T) {
+		gen := calendar.NewGenerator(2026, 1, "Asia/Hanoi", calendar.WithoutFestivals(calendar.FestivalIDs...), calendar.WithRules(rules[0]))
+		events, err := gen.Generate("")
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, "2026-06-02", events[0].Date.Format(time.DateOnly))
+	})
+}
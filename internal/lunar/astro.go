@@ -0,0 +1,134 @@
+package lunar
+
+import "math"
+
+// The astronomical routines below follow Hồ Ngọc Đức's algorithms for the
+// Vietnamese lunar calendar (https://www.informatik.uni-leipzig.de/~duc/amlich/),
+// which are in turn based on Jean Meeus' "Astronomical Algorithms". Julian day
+// numbers are integers counting days since noon 1 January 4713 BC; Julian dates
+// are the fractional form of the same count.
+
+// julianDay returns the Julian day number of the Gregorian date, or of the
+// Julian calendar date for days before the 1582 calendar reform.
+func julianDay(year, month, day int) int {
+	a := (14 - month) / 12
+	y := year + 4800 - a
+	m := month + 12*a - 3
+	jd := day + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+	if jd < 2299161 {
+		jd = day + (153*m+2)/5 + 365*y + y/4 - 32083
+	}
+	return jd
+}
+
+// fromJulianDay is the inverse of julianDay.
+func fromJulianDay(jd int) (year, month, day int) {
+	var b, c int
+	if jd > 2299160 {
+		a := jd + 32044
+		b = (4*a + 3) / 146097
+		c = a - (b*146097)/4
+	} else {
+		c = jd + 32082
+	}
+	d := (4*c + 3) / 1461
+	e := c - (1461*d)/4
+	m := (5*e + 2) / 153
+	day = e - (153*m+2)/5 + 1
+	month = m + 3 - 12*(m/10)
+	year = b*100 + d - 4800 + m/10
+	return year, month, day
+}
+
+// newMoon returns the Julian date (UT) of the k-th new moon after the one of
+// 1 January 1900.
+func newMoon(k float64) float64 {
+	t := k / 1236.85 // Julian centuries from 1900 January 0.5
+	t2 := t * t
+	t3 := t2 * t
+	dr := math.Pi / 180
+	jd1 := 2415020.75933 + 29.53058868*k + 0.0001178*t2 - 0.000000155*t3
+	jd1 += 0.00033 * math.Sin((166.56+132.87*t-0.009173*t2)*dr)     // Mean new moon
+	m := 359.2242 + 29.10535608*k - 0.0000333*t2 - 0.00000347*t3    // Sun's mean anomaly
+	mpr := 306.0253 + 385.81691806*k + 0.0107306*t2 + 0.00001236*t3 // Moon's mean anomaly
+	f := 21.2964 + 390.67050646*k - 0.0016528*t2 - 0.00000239*t3    // Moon's argument of latitude
+	c1 := (0.1734-0.000393*t)*math.Sin(m*dr) + 0.0021*math.Sin(2*dr*m)
+	c1 = c1 - 0.4068*math.Sin(mpr*dr) + 0.0161*math.Sin(dr*2*mpr)
+	c1 = c1 - 0.0004*math.Sin(dr*3*mpr)
+	c1 = c1 + 0.0104*math.Sin(dr*2*f) - 0.0051*math.Sin(dr*(m+mpr))
+	c1 = c1 - 0.0074*math.Sin(dr*(m-mpr)) + 0.0004*math.Sin(dr*(2*f+m))
+	c1 = c1 - 0.0004*math.Sin(dr*(2*f-m)) - 0.0006*math.Sin(dr*(2*f+mpr))
+	c1 = c1 + 0.0010*math.Sin(dr*(2*f-mpr)) + 0.0005*math.Sin(dr*(2*mpr+m))
+	var deltaT float64
+	if t < -11 {
+		deltaT = 0.001 + 0.000839*t + 0.0002261*t2 - 0.00000845*t3 - 0.000000081*t*t3
+	} else {
+		deltaT = -0.000278 + 0.000265*t + 0.000262*t2
+	}
+	return jd1 + c1 - deltaT
+}
+
+// sunLongitude returns the sun's longitude, in radians normalized to
+// [0, 2π), at the Julian date jd.
+func sunLongitude(jd float64) float64 {
+	t := (jd - 2451545.0) / 36525 // Julian centuries from 2000-01-01 12:00:00 GMT
+	t2 := t * t
+	dr := math.Pi / 180
+	m := 357.52910 + 35999.05030*t - 0.0001559*t2 - 0.00000048*t*t2 // mean anomaly, degree
+	l0 := 280.46645 + 36000.76983*t + 0.0003032*t2                  // mean longitude, degree
+	dl := (1.914600 - 0.004817*t - 0.000014*t2) * math.Sin(dr*m)
+	dl += (0.019993-0.000101*t)*math.Sin(dr*2*m) + 0.00029*math.Sin(dr*3*m)
+	l := (l0 + dl) * dr
+	return l - 2*math.Pi*math.Floor(l/(2*math.Pi))
+}
+
+// newMoonDay returns the Julian day number of the local day, at the meridian
+// in effect at the time, on which the k-th new moon falls.
+func newMoonDay(k int, m meridian) int {
+	jd := newMoon(float64(k))
+	return int(math.Floor(jd + 0.5 + m.at(jd)))
+}
+
+// sunSegment returns which of the twelve 30° segments of the ecliptic the sun
+// is in at local midnight starting day jd. Segment 9 starts at the winter
+// solstice.
+func sunSegment(jd int, m meridian) int {
+	midnight := float64(jd) - 0.5
+	return int(math.Floor(sunLongitude(midnight-m.at(midnight)) / math.Pi * 6))
+}
+
+// month11 returns the Julian day number on which lunar month 11, the month
+// containing the winter solstice, of Gregorian year year starts.
+func month11(year int, m meridian) int {
+	off := julianDay(year, 12, 31) - 2415021
+	k := int(math.Floor(float64(off) / 29.530588853))
+	nm := newMoonDay(k, m)
+	if sunSegment(nm, m) >= 9 {
+		nm = newMoonDay(k-1, m)
+	}
+	return nm
+}
+
+// newMoonIndex returns k such that newMoonDay(k) is the day jd starting a
+// lunar month.
+func newMoonIndex(jd int) int {
+	return int(math.Floor(0.5 + (float64(jd)-2415021.076998695)/29.530588853))
+}
+
+// leapMonthOffset returns the position, counting lunar month 11 starting on
+// a11 as 0, of the first month in which the sun does not enter a new segment
+// of the ecliptic. That month is the leap month.
+func leapMonthOffset(a11 int, m meridian) int {
+	k := newMoonIndex(a11)
+	i := 1
+	arc := sunSegment(newMoonDay(k+i, m), m)
+	for {
+		last := arc
+		i++
+		arc = sunSegment(newMoonDay(k+i, m), m)
+		if arc == last || i >= 14 {
+			break
+		}
+	}
+	return i - 1
+}
@@ -0,0 +1,87 @@
+package lunar
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	// Cans are the ten Heavenly Stems (Thiên Can).
+	Cans = [10]string{"Giáp", "Ất", "Bính", "Đinh", "Mậu", "Kỷ", "Canh", "Tân", "Nhâm", "Quý"}
+	// Chis are the twelve Earthly Branches (Địa Chi).
+	Chis = [12]string{"Tý", "Sửu", "Dần", "Mão", "Thìn", "Tỵ", "Ngọ", "Mùi", "Thân", "Dậu", "Tuất", "Hợi"}
+)
+
+// CanChi is a sexagenary name, a Heavenly Stem paired with an Earthly Branch,
+// e.g. Bính Ngọ.
+type CanChi struct {
+	Can int // index into Cans
+	Chi int // index into Chis
+}
+
+func (c CanChi) String() string {
+	return Cans[c.Can] + " " + Chis[c.Chi]
+}
+
+// YearCanChi returns the name of the lunar year.
+func YearCanChi(year int) CanChi {
+	return CanChi{Can: mod(year+6, 10), Chi: mod(year+8, 12)}
+}
+
+// MonthCanChi returns the name of the lunar month. A leap month shares the
+// name of the regular month it follows.
+func MonthCanChi(year, month int) CanChi {
+	return CanChi{Can: mod(year*12+month+3, 10), Chi: mod(month+1, 12)}
+}
+
+// DayCanChi returns the name of the solar day t. Only the year, month and
+// day of t are used.
+func DayCanChi(t time.Time) CanChi {
+	jd := julianDay(t.Year(), int(t.Month()), t.Day())
+	return CanChi{Can: mod(jd+9, 10), Chi: mod(jd+1, 12)}
+}
+
+// HourCanChi returns the name of the two-hour period (giờ) containing t, in
+// t's location. Giờ Tý runs from 23:00 to 01:00, and its first hour already
+// counts towards the next day when deriving the stem.
+func HourCanChi(t time.Time) CanChi {
+	chi := (t.Hour() + 1) / 2 % 12
+	day := t
+	if t.Hour() == 23 {
+		day = t.AddDate(0, 0, 1)
+	}
+	return CanChi{Can: mod(DayCanChi(day).Can*2+chi, 10), Chi: chi}
+}
+
+// Sexagenary holds the Can Chi names of a day.
+type Sexagenary struct {
+	Year  CanChi
+	Month CanChi
+	Day   CanChi
+	Leap  bool
+}
+
+// SexagenaryOf returns the Can Chi names of the solar day t, which is the
+// lunar date ld of lunar year year.
+func SexagenaryOf(year int, ld Date, t time.Time) Sexagenary {
+	return Sexagenary{
+		Year:  YearCanChi(year),
+		Month: MonthCanChi(year, ld.Month),
+		Day:   DayCanChi(t),
+		Leap:  ld.Leap,
+	}
+}
+
+// String formats the names the way Vietnamese calendars print them, e.g.
+// "ngày Giáp Tý, tháng Canh Dần, năm Bính Ngọ".
+func (s Sexagenary) String() string {
+	month := s.Month.String()
+	if s.Leap {
+		month += " nhuận"
+	}
+	return fmt.Sprintf("ngày %s, tháng %s, năm %s", s.Day, month, s.Year)
+}
+
+func mod(a, b int) int {
+	return (a%b + b) % b
+}
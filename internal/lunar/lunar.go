@@ -1,14 +1,14 @@
 package lunar
 
 import (
+	"sync"
 	"time"
-
-	"github.com/hungtrd/amlich"
 )
 
 type Date struct {
 	Day   int
 	Month int
+	Leap  bool
 }
 
 type Range struct {
@@ -23,6 +23,8 @@ type FindOption func(*findConfig)
 type findConfig struct {
 	findRange Range
 	timezone  string
+	loc       *time.Location
+	meridian  *meridian
 }
 
 func WithRange(r Range) FindOption {
@@ -31,12 +33,90 @@ func WithRange(r Range) FindOption {
 	}
 }
 
+// WithTimezone sets the time zone, by name, of the returned dates and the
+// meridian used to decide on which day a new moon or solar term falls. A
+// name that LoadLocation cannot resolve falls back to UTC.
 func WithTimezone(tz string) FindOption {
 	return func(c *findConfig) {
 		c.timezone = tz
 	}
 }
 
+// WithLocation is like WithTimezone but takes the location directly.
+func WithLocation(loc *time.Location) FindOption {
+	return func(c *findConfig) {
+		c.loc = loc
+	}
+}
+
+// Region selects which historical calendar WithHistoricalMeridian follows
+// for the years Vietnam was divided.
+type Region int
+
+const (
+	North Region = iota
+	South
+)
+
+// WithHistoricalMeridian dates each new moon at the meridian Vietnam
+// actually used at the time rather than the configured zone's. The calendar
+// was computed at UTC+8, the meridian of the Chinese calendar, until the
+// North switched to UTC+7 on 8 August 1967, which is why Tết Mậu Thân fell a
+// day earlier in Hanoi than in Saigon. The South kept UTC+8 until
+// reunification and switched from 1976. Returned dates are still in the
+// configured zone.
+func WithHistoricalMeridian(region Region) FindOption {
+	m := meridian{offset: 7.0 / 24, before: 8.0 / 24, switchAt: localMidnight(1967, 8, 8, 8.0/24)}
+	if region == South {
+		m.switchAt = localMidnight(1976, 1, 1, 8.0/24)
+	}
+	return func(c *findConfig) {
+		c.meridian = &m
+	}
+}
+
+// localMidnight returns the Julian date (UT) of the midnight starting the
+// day at the offset east of UTC, in fractions of a day.
+func localMidnight(year, month, day int, offset float64) float64 {
+	return float64(julianDay(year, month, day)) - 0.5 - offset
+}
+
+// aliases are zone names in common use that are not in the IANA database.
+var aliases = map[string]*time.Location{
+	"Asia/Hanoi": time.FixedZone("Asia/Hanoi", 7*60*60),
+}
+
+// locations caches loaded time zones, as time.LoadLocation reads the zone
+// database on every call.
+var locations sync.Map
+
+// LoadLocation is like time.LoadLocation but also accepts Asia/Hanoi, a fixed
+// UTC+7 zone, which is what the Vietnamese lunar calendar is computed for.
+func LoadLocation(name string) (*time.Location, error) {
+	if l, ok := aliases[name]; ok {
+		return l, nil
+	}
+	if l, ok := locations.Load(name); ok {
+		return l.(*time.Location), nil
+	}
+	l, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, l)
+	return l, nil
+}
+
+func (c *findConfig) location() *time.Location {
+	if c.loc != nil {
+		return c.loc
+	}
+	if l, err := LoadLocation(c.timezone); err == nil {
+		return l
+	}
+	return time.UTC
+}
+
 var (
 	Tet                   = Date{Month: 1, Day: 1}
 	HungKingCommemoration = Date{Day: 10, Month: 3}
@@ -47,11 +127,13 @@ var (
 	TetRange = Range{StartMonth: 1, StartDay: 21, EndMonth: 2, EndDay: 20}
 )
 
+// FindLunarDate returns the first day of the Gregorian year matching the
+// lunar date, or the zero time if none does. Lunar months 11 and 12 can
+// occur zero or two times in a Gregorian year; use ToSolar to look a date up
+// by lunar year instead.
 func FindLunarDate(year int, ld Date, opts ...FindOption) time.Time {
-	cfg := &findConfig{timezone: "Asia/Hanoi"}
-	for _, opt := range opts {
-		opt(cfg)
-	}
+	cfg := newFindConfig(opts)
+	loc := cfg.location()
 
 	startMonth, endMonth := 1, 12
 	startDay, endDay := 1, 31
@@ -65,27 +147,134 @@ func FindLunarDate(year int, ld Date, opts ...FindOption) time.Time {
 		endDay = cfg.findRange.EndDay
 	}
 
-	for month := startMonth; month <= endMonth; month++ {
-		dayStart := 1
-		dayEnd := 31
-		if month == startMonth {
-			dayStart = startDay
+	from := julianDay(year, startMonth, startDay)
+	to := julianDay(year, endMonth, endDay)
+
+	// A Gregorian year starts in the previous lunar year and ends in the
+	// current one, so the earliest match is in one of the two
+	for _, lunarYear := range []int{year - 1, year} {
+		jd, ok := toJulianDay(lunarYear, ld, cfg)
+		if ok && jd >= from && jd <= to {
+			return dateOf(jd, loc)
 		}
-		if month == endMonth {
-			dayEnd = endDay
+	}
+	return time.Time{}
+}
+
+// ToSolar returns the solar date of ld in lunar year year, i.e. the year that
+// starts at Tết of Gregorian year year. Every lunar date maps to exactly one
+// solar date; the zero time is returned if ld does not exist in that year,
+// such as day 30 of a 29-day month or a leap month the year does not have.
+func ToSolar(year int, ld Date, opts ...FindOption) time.Time {
+	cfg := newFindConfig(opts)
+	jd, ok := toJulianDay(year, ld, cfg)
+	if !ok {
+		return time.Time{}
+	}
+	return dateOf(jd, cfg.location())
+}
+
+// FromSolar returns the lunar year and date of the solar day t. Only the
+// year, month and day of t are used.
+func FromSolar(t time.Time, opts ...FindOption) (int, Date) {
+	cfg := newFindConfig(opts)
+	jd := julianDay(t.Year(), int(t.Month()), t.Day())
+
+	// Tết always falls in January or February, so the day belongs either to
+	// the lunar year of the same number or to the previous one
+	year := t.Year()
+	table := tableFor(year, cfg.meridianFor(year))
+	if jd < table.start() {
+		year--
+		table = tableFor(year, cfg.meridianFor(year))
+	}
+	for _, mo := range table.months {
+		if jd < mo.start+mo.days {
+			return year, Date{Day: jd - mo.start + 1, Month: mo.month, Leap: mo.leap}
+		}
+	}
+	return year, Date{}
+}
+
+func newFindConfig(opts []FindOption) *findConfig {
+	cfg := &findConfig{timezone: "Asia/Hanoi"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+func toJulianDay(year int, ld Date, cfg *findConfig) (int, bool) {
+	if ld.Day < 1 {
+		return 0, false
+	}
+	mo, ok := tableFor(year, cfg.meridianFor(year)).find(ld.Month, ld.Leap)
+	if !ok || ld.Day > mo.days {
+		return 0, false
+	}
+	return mo.start + ld.Day - 1, true
+}
+
+func dateOf(jd int, loc *time.Location) time.Time {
+	year, month, day := fromJulianDay(jd)
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+}
+
+// Month is one month of a lunar year.
+type Month struct {
+	Month int
+	Leap  bool
+	// Start is the solar date of day 1.
+	Start time.Time
+	// Days is 30 for a full month (tháng đủ) and 29 for a short one
+	// (tháng thiếu).
+	Days int
+}
+
+// Year lists the months of a lunar year in order, from Tết to the last day
+// before the next Tết.
+type Year struct {
+	Year   int
+	Months []Month
+}
+
+// YearInfo returns the months of lunar year year with their start dates,
+// lengths and leap status.
+func YearInfo(year int, opts ...FindOption) Year {
+	cfg := newFindConfig(opts)
+	loc := cfg.location()
+	table := tableFor(year, cfg.meridianFor(year))
+
+	months := make([]Month, 0, len(table.months))
+	for _, mo := range table.months {
+		months = append(months, Month{
+			Month: mo.month,
+			Leap:  mo.leap,
+			Start: dateOf(mo.start, loc),
+			Days:  mo.days,
+		})
+	}
+	return Year{Year: year, Months: months}
+}
+
+// Month returns the regular or leap month of the year, and false if the year
+// does not have it.
+func (y Year) Month(month int, leap bool) (Month, bool) {
+	for _, m := range y.Months {
+		if m.Month == month && m.Leap == leap {
+			return m, true
 		}
+	}
+	return Month{}, false
+}
 
-		for day := dayStart; day <= dayEnd; day++ {
-			loc := time.UTC
-			if l, err := time.LoadLocation(cfg.timezone); err == nil {
-				loc = l
-			}
-			t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
-			lunar := amlich.New(t)
-			if lunar.Month == ld.Month && lunar.Day == ld.Day {
-				return t
-			}
+// LeapMonth returns the number of the month the leap month repeats, or 0 if
+// the year has none.
+func (y Year) LeapMonth() int {
+	for _, m := range y.Months {
+		if m.Leap {
+			return m.Month
 		}
 	}
-	return time.Time{}
+	return 0
 }
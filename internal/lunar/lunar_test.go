@@ -1,10 +1,13 @@
 package lunar_test
 
 import (
+	"math"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/hpcsc/vietnamese-lunar-calendar/internal/lunar"
+	"github.com/hungtrd/amlich"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,7 +37,7 @@ func TestFindLunarDate(t *testing.T) {
 		result := lunar.FindLunarDate(2026, lunar.VuLan)
 		require.Equal(t, 2026, result.Year())
 		require.Equal(t, time.August, result.Month())
-		require.Equal(t, 26, result.Day())
+		require.Equal(t, 27, result.Day())
 	})
 
 	t.Run("TrungThu", func(t *testing.T) {
@@ -54,6 +57,25 @@ func TestFindLunarDate(t *testing.T) {
 		require.True(t, result.IsZero())
 	})
 
+	t.Run("regular month in a leap year", func(t *testing.T) {
+		result := lunar.FindLunarDate(2025, lunar.Date{Month: 6, Day: 15}, lunar.WithTimezone("Asia/Ho_Chi_Minh"))
+		require.Equal(t, 2025, result.Year())
+		require.Equal(t, time.July, result.Month())
+		require.Equal(t, 9, result.Day())
+	})
+
+	t.Run("leap month", func(t *testing.T) {
+		result := lunar.FindLunarDate(2025, lunar.Date{Month: 6, Day: 15, Leap: true}, lunar.WithTimezone("Asia/Ho_Chi_Minh"))
+		require.Equal(t, 2025, result.Year())
+		require.Equal(t, time.August, result.Month())
+		require.Equal(t, 8, result.Day())
+	})
+
+	t.Run("leap month not in year returns zero", func(t *testing.T) {
+		result := lunar.FindLunarDate(2026, lunar.Date{Month: 6, Day: 15, Leap: true}, lunar.WithTimezone("Asia/Ho_Chi_Minh"))
+		require.True(t, result.IsZero())
+	})
+
 	t.Run("WithRange", func(t *testing.T) {
 		t.Run("return date if within range", func(t *testing.T) {
 			result := lunar.FindLunarDate(2026, lunar.Tet, lunar.WithRange(lunar.TetRange))
@@ -69,3 +91,403 @@ func TestFindLunarDate(t *testing.T) {
 		})
 	})
 }
+
+func TestToSolar(t *testing.T) {
+	tz := lunar.WithTimezone("Asia/Ho_Chi_Minh")
+
+	t.Run("Tet", func(t *testing.T) {
+		result := lunar.ToSolar(2026, lunar.Tet, tz)
+		require.Equal(t, time.Date(2026, time.February, 17, 0, 0, 0, 0, result.Location()), result)
+	})
+
+	t.Run("month 12 falls in the next Gregorian year", func(t *testing.T) {
+		result := lunar.ToSolar(2025, lunar.Date{Month: 12, Day: 20}, tz)
+		require.Equal(t, time.Date(2026, time.February, 7, 0, 0, 0, 0, result.Location()), result)
+	})
+
+	t.Run("leap month", func(t *testing.T) {
+		result := lunar.ToSolar(2025, lunar.Date{Month: 6, Day: 15, Leap: true}, tz)
+		require.Equal(t, time.Date(2025, time.August, 8, 0, 0, 0, 0, result.Location()), result)
+	})
+
+	t.Run("leap month not in year returns zero", func(t *testing.T) {
+		result := lunar.ToSolar(2026, lunar.Date{Month: 6, Day: 15, Leap: true}, tz)
+		require.True(t, result.IsZero())
+	})
+
+	t.Run("day 30 of a 29-day month returns zero", func(t *testing.T) {
+		result := lunar.ToSolar(2026, lunar.Date{Month: 2, Day: 30}, tz)
+		require.True(t, result.IsZero())
+	})
+
+	t.Run("invalid lunar month returns zero", func(t *testing.T) {
+		result := lunar.ToSolar(2026, lunar.Date{Month: 13, Day: 1}, tz)
+		require.True(t, result.IsZero())
+	})
+}
+
+func TestFromSolar(t *testing.T) {
+	t.Run("Tet", func(t *testing.T) {
+		year, ld := lunar.FromSolar(time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC), lunar.WithTimezone("Asia/Ho_Chi_Minh"))
+		require.Equal(t, 2026, year)
+		require.Equal(t, lunar.Tet, ld)
+	})
+
+	t.Run("before Tet belongs to the previous lunar year", func(t *testing.T) {
+		year, ld := lunar.FromSolar(time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC), lunar.WithTimezone("Asia/Ho_Chi_Minh"))
+		require.Equal(t, 2025, year)
+		require.Equal(t, lunar.Date{Day: 29, Month: 12}, ld)
+	})
+
+	t.Run("leap month", func(t *testing.T) {
+		year, ld := lunar.FromSolar(time.Date(2025, time.August, 8, 0, 0, 0, 0, time.UTC), lunar.WithTimezone("Asia/Ho_Chi_Minh"))
+		require.Equal(t, 2025, year)
+		require.Equal(t, lunar.Date{Day: 15, Month: 6, Leap: true}, ld)
+	})
+
+	t.Run("matches the amlich conversion day by day", func(t *testing.T) {
+		// Asia/Bangkok has been UTC+7 since 1920
+		tz := lunar.WithTimezone("Asia/Bangkok")
+		for d := time.Date(1920, time.January, 1, 0, 0, 0, 0, time.UTC); d.Year() < 2100; d = d.AddDate(0, 0, 1) {
+			day, month, year, leap := amlich.Solar2Lunar(d.Day(), int(d.Month()), d.Year(), 7)
+			if day == 0 {
+				// amlich picks the wrong new moon when one falls right
+				// at local midnight and returns day 0 of the next month
+				continue
+			}
+			expected := lunar.Date{Day: day, Month: month, Leap: leap == 1}
+
+			lunarYear, ld := lunar.FromSolar(d, tz)
+			require.Equal(t, year, lunarYear, "lunar year of %s", d.Format(time.DateOnly))
+			require.Equal(t, expected, ld, "lunar date of %s", d.Format(time.DateOnly))
+
+			solar := lunar.ToSolar(lunarYear, ld, tz)
+			require.Equal(t, d.Format(time.DateOnly), solar.Format(time.DateOnly))
+		}
+	})
+}
+
+// findByScan is the day-by-day search FindLunarDate used before it converted
+// directly, kept to benchmark against.
+func findByScan(year int, ld lunar.Date, loc *time.Location) time.Time {
+	for t := time.Date(year, time.January, 1, 0, 0, 0, 0, loc); t.Year() == year; t = t.AddDate(0, 0, 1) {
+		l := amlich.New(t)
+		if l.Month == ld.Month && l.Day == ld.Day && l.Leap == ld.Leap {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func BenchmarkFindLunarDate(b *testing.B) {
+	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	require.NoError(b, err)
+	ld := lunar.Date{Day: 15, Month: 12}
+
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; b.Loop(); i++ {
+			findByScan(1950+i%150, ld, loc)
+		}
+	})
+
+	b.Run("direct", func(b *testing.B) {
+		tz := lunar.WithTimezone("Asia/Ho_Chi_Minh")
+		for i := 0; b.Loop(); i++ {
+			lunar.FindLunarDate(1950+i%150, ld, tz)
+		}
+	})
+}
+
+func BenchmarkToSolar(b *testing.B) {
+	tz := lunar.WithTimezone("Asia/Ho_Chi_Minh")
+	for i := 0; b.Loop(); i++ {
+		lunar.ToSolar(1950+i%150, lunar.TrungThu, tz)
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	t.Run("Asia/Hanoi is UTC+7", func(t *testing.T) {
+		loc, err := lunar.LoadLocation("Asia/Hanoi")
+		require.NoError(t, err)
+		_, offset := time.Date(2026, time.January, 1, 0, 0, 0, 0, loc).Zone()
+		require.Equal(t, 7*60*60, offset)
+	})
+
+	t.Run("Chinese calendar differs from the Vietnamese one", func(t *testing.T) {
+		tests := []struct {
+			year       int
+			vietnamese time.Time
+			chinese    time.Time
+		}{
+			{1985, time.Date(1985, time.January, 21, 0, 0, 0, 0, time.UTC), time.Date(1985, time.February, 20, 0, 0, 0, 0, time.UTC)},
+			{2007, time.Date(2007, time.February, 17, 0, 0, 0, 0, time.UTC), time.Date(2007, time.February, 18, 0, 0, 0, 0, time.UTC)},
+		}
+
+		for _, tt := range tests {
+			vietnamese := lunar.ToSolar(tt.year, lunar.Tet, lunar.WithTimezone("Asia/Hanoi"))
+			require.Equal(t, tt.vietnamese.Format(time.DateOnly), vietnamese.Format(time.DateOnly))
+
+			chinese := lunar.ToSolar(tt.year, lunar.Tet, lunar.WithTimezone("Asia/Shanghai"))
+			require.Equal(t, tt.chinese.Format(time.DateOnly), chinese.Format(time.DateOnly))
+		}
+	})
+
+	t.Run("uses non-integer offsets", func(t *testing.T) {
+		// The new moon of 8/4/2024 was at 18:21 UTC, i.e. 23:51 in
+		// Asia/Kolkata (+5:30) but 00:06 the next day in Asia/Kathmandu (+5:45)
+		day := time.Date(2024, time.April, 8, 0, 0, 0, 0, time.UTC)
+
+		_, kolkata := lunar.FromSolar(day, lunar.WithTimezone("Asia/Kolkata"))
+		require.Equal(t, 1, kolkata.Day)
+
+		_, kathmandu := lunar.FromSolar(day, lunar.WithTimezone("Asia/Kathmandu"))
+		require.NotEqual(t, 1, kathmandu.Day)
+	})
+
+	t.Run("WithLocation", func(t *testing.T) {
+		result := lunar.ToSolar(2007, lunar.Tet, lunar.WithLocation(time.FixedZone("UTC+8", 8*60*60)))
+		require.Equal(t, "2007-02-18", result.Format(time.DateOnly))
+	})
+}
+
+func TestWithHistoricalMeridian(t *testing.T) {
+	tests := []struct {
+		name   string
+		year   int
+		region lunar.Region
+		tet    string
+	}{
+		{"North before 1968 uses UTC+8", 1965, lunar.North, "1965-02-02"},
+		{"South before 1968 uses UTC+8", 1965, lunar.South, "1965-02-02"},
+		{"North from 1968 uses UTC+7", 1968, lunar.North, "1968-01-29"},
+		{"South until 1975 uses UTC+8", 1968, lunar.South, "1968-01-30"},
+		{"North in 1969", 1969, lunar.North, "1969-02-16"},
+		{"South in 1969", 1969, lunar.South, "1969-02-17"},
+		{"North after reunification", 2026, lunar.North, "2026-02-17"},
+		{"South after reunification", 2026, lunar.South, "2026-02-17"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := lunar.ToSolar(tt.year, lunar.Tet, lunar.WithHistoricalMeridian(tt.region))
+			require.Equal(t, tt.tet, result.Format(time.DateOnly))
+		})
+	}
+
+	t.Run("months follow each other across the switch", func(t *testing.T) {
+		for _, tt := range []struct {
+			region lunar.Region
+			years  []int
+		}{
+			{lunar.North, []int{1966, 1967, 1968, 1969}},
+			{lunar.South, []int{1974, 1975, 1976, 1977}},
+		} {
+			var end time.Time
+			for _, year := range tt.years {
+				for _, m := range lunar.YearInfo(year, lunar.WithHistoricalMeridian(tt.region)).Months {
+					if !end.IsZero() {
+						require.Equal(t, end, m.Start, "region %d, month %d of %d", tt.region, m.Month, year)
+					}
+					end = m.Start.AddDate(0, 0, m.Days)
+				}
+			}
+		}
+	})
+
+	t.Run("last day of 1967 is the day before Tết 1968 in the North", func(t *testing.T) {
+		last := lunar.ToSolar(1967, lunar.Date{Day: 30, Month: 12}, lunar.WithHistoricalMeridian(lunar.North))
+		if last.IsZero() {
+			last = lunar.ToSolar(1967, lunar.Date{Day: 29, Month: 12}, lunar.WithHistoricalMeridian(lunar.North))
+		}
+		tet := lunar.ToSolar(1968, lunar.Tet, lunar.WithHistoricalMeridian(lunar.North))
+
+		require.Equal(t, tet.AddDate(0, 0, -1), last)
+	})
+
+	t.Run("converts solar dates", func(t *testing.T) {
+		day := time.Date(1968, time.January, 29, 0, 0, 0, 0, time.UTC)
+
+		year, ld := lunar.FromSolar(day, lunar.WithHistoricalMeridian(lunar.North))
+		require.Equal(t, 1968, year)
+		require.Equal(t, lunar.Tet, ld)
+
+		year, ld = lunar.FromSolar(day, lunar.WithHistoricalMeridian(lunar.South))
+		require.Equal(t, 1967, year)
+		require.Equal(t, 12, ld.Month)
+	})
+}
+
+func TestCanChi(t *testing.T) {
+	t.Run("year", func(t *testing.T) {
+		require.Equal(t, "Bính Ngọ", lunar.YearCanChi(2026).String())
+		require.Equal(t, "Mậu Thân", lunar.YearCanChi(1968).String())
+		require.Equal(t, "Giáp Tý", lunar.YearCanChi(1984).String())
+	})
+
+	t.Run("month", func(t *testing.T) {
+		require.Equal(t, "Canh Dần", lunar.MonthCanChi(2026, 1).String())
+		require.Equal(t, "Tân Sửu", lunar.MonthCanChi(2026, 12).String())
+	})
+
+	t.Run("hour", func(t *testing.T) {
+		// 17/2/2026 is a Nhâm Tuất day, whose giờ Tý is Canh Tý
+		day := time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC)
+		require.Equal(t, "Canh Tý", lunar.HourCanChi(day.Add(23*time.Hour)).String())
+		require.Equal(t, "Canh Tý", lunar.HourCanChi(day.Add(24*time.Hour)).String())
+		require.Equal(t, "Tân Sửu", lunar.HourCanChi(day.Add(25*time.Hour)).String())
+		require.Equal(t, "Tân Hợi", lunar.HourCanChi(day.Add(46*time.Hour)).String())
+	})
+
+	t.Run("matches amlich", func(t *testing.T) {
+		for d := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC); d.Year() < 2030; d = d.AddDate(0, 0, 7) {
+			loc := time.FixedZone("UTC+7", 7*60*60)
+			expected := amlich.New(time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc))
+
+			year, ld := lunar.FromSolar(d, lunar.WithLocation(loc))
+			s := lunar.SexagenaryOf(year, ld, d)
+			require.Equal(t, expected.DayAlias(), s.Day.String())
+			require.Equal(t, expected.YearAlias(), s.Year.String())
+		}
+	})
+
+	t.Run("formats in Vietnamese", func(t *testing.T) {
+		day := time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC)
+		year, ld := lunar.FromSolar(day)
+		require.Equal(t, "ngày Nhâm Tuất, tháng Canh Dần, năm Bính Ngọ", lunar.SexagenaryOf(year, ld, day).String())
+	})
+
+	t.Run("formats leap month", func(t *testing.T) {
+		day := time.Date(2025, time.August, 8, 0, 0, 0, 0, time.UTC)
+		year, ld := lunar.FromSolar(day)
+		require.Contains(t, lunar.SexagenaryOf(year, ld, day).String(), "tháng Quý Mùi nhuận")
+	})
+}
+
+func TestSolarTerms(t *testing.T) {
+	terms := lunar.SolarTerms(2026)
+
+	t.Run("returns 24 terms in order", func(t *testing.T) {
+		require.Len(t, terms, 24)
+		require.Equal(t, "Tiểu Hàn", terms[0].Name)
+		require.Equal(t, "Đông Chí", terms[23].Name)
+		for i := 1; i < len(terms); i++ {
+			require.True(t, terms[i].Time.After(terms[i-1].Time))
+			require.Equal(t, 2026, terms[i].Time.Year())
+		}
+	})
+
+	t.Run("matches published equinox and solstice instants", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			expected time.Time
+		}{
+			{"Xuân Phân", time.Date(2026, time.March, 20, 14, 46, 0, 0, time.UTC)},
+			{"Hạ Chí", time.Date(2026, time.June, 21, 8, 24, 0, 0, time.UTC)},
+			{"Thu Phân", time.Date(2026, time.September, 23, 0, 5, 0, 0, time.UTC)},
+			{"Đông Chí", time.Date(2026, time.December, 21, 20, 50, 0, 0, time.UTC)},
+		}
+
+		for _, tt := range tests {
+			idx := slices.IndexFunc(terms, func(term lunar.SolarTerm) bool { return term.Name == tt.name })
+			require.NotEqual(t, -1, idx, tt.name)
+			require.WithinDuration(t, tt.expected, terms[idx].Time, 2*time.Minute, tt.name)
+		}
+	})
+
+	t.Run("Thanh Minh", func(t *testing.T) {
+		idx := slices.IndexFunc(terms, func(term lunar.SolarTerm) bool { return term.Name == "Thanh Minh" })
+		require.Equal(t, 15, terms[idx].Longitude)
+		require.Equal(t, "2026-04-05", terms[idx].Time.In(time.FixedZone("UTC+7", 7*60*60)).Format(time.DateOnly))
+	})
+
+	t.Run("sun longitude at the term instant", func(t *testing.T) {
+		for _, term := range terms {
+			longitude := lunar.SunLongitude(term.Time)
+			diff := math.Mod(longitude-float64(term.Longitude)+540, 360) - 180
+			require.InDelta(t, 0, diff, 0.001, term.Name)
+		}
+	})
+}
+
+func TestMoonPhases(t *testing.T) {
+	from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("new moons", func(t *testing.T) {
+		result := lunar.NewMoons(from, to)
+		require.Len(t, result, 2)
+		require.WithinDuration(t, time.Date(2026, time.January, 18, 19, 52, 0, 0, time.UTC), result[0], 5*time.Minute)
+		require.WithinDuration(t, time.Date(2026, time.February, 17, 12, 1, 0, 0, time.UTC), result[1], 5*time.Minute)
+	})
+
+	t.Run("full moons", func(t *testing.T) {
+		result := lunar.FullMoons(from, to)
+		require.Len(t, result, 2)
+		require.WithinDuration(t, time.Date(2026, time.January, 3, 10, 3, 0, 0, time.UTC), result[0], 5*time.Minute)
+		require.WithinDuration(t, time.Date(2026, time.February, 1, 22, 9, 0, 0, time.UTC), result[1], 5*time.Minute)
+	})
+
+	t.Run("new moons start the lunar months", func(t *testing.T) {
+		loc, err := lunar.LoadLocation("Asia/Hanoi")
+		require.NoError(t, err)
+		for _, nm := range lunar.NewMoons(from, from.AddDate(10, 0, 0)) {
+			_, ld := lunar.FromSolar(nm.In(loc))
+			require.Equal(t, 1, ld.Day, "new moon at %s", nm)
+		}
+	})
+}
+
+func TestYearInfo(t *testing.T) {
+	t.Run("regular year", func(t *testing.T) {
+		year := lunar.YearInfo(2026)
+
+		require.Equal(t, 2026, year.Year)
+		require.Len(t, year.Months, 12)
+		require.Equal(t, 0, year.LeapMonth())
+		require.Equal(t, "2026-02-17", year.Months[0].Start.Format(time.DateOnly))
+
+		total := 0
+		for i, m := range year.Months {
+			require.Equal(t, i+1, m.Month)
+			require.Contains(t, []int{29, 30}, m.Days)
+			total += m.Days
+		}
+		require.Equal(t, "2027-02-06", year.Months[0].Start.AddDate(0, 0, total).Format(time.DateOnly))
+	})
+
+	t.Run("leap year", func(t *testing.T) {
+		year := lunar.YearInfo(2025)
+
+		require.Len(t, year.Months, 13)
+		require.Equal(t, 6, year.LeapMonth())
+
+		leap, ok := year.Month(6, true)
+		require.True(t, ok)
+		require.Equal(t, "2025-07-25", leap.Start.Format(time.DateOnly))
+		require.Equal(t, leap, year.Months[6])
+		require.Equal(t, 7, year.Months[7].Month)
+	})
+
+	t.Run("month lengths", func(t *testing.T) {
+		year := lunar.YearInfo(2026)
+
+		february, ok := year.Month(2, false)
+		require.True(t, ok)
+		require.Equal(t, 29, february.Days)
+
+		_, ok = year.Month(2, true)
+		require.False(t, ok)
+	})
+
+	t.Run("month lengths match ToSolar", func(t *testing.T) {
+		for y := 1990; y < 2050; y++ {
+			for _, m := range lunar.YearInfo(y).Months {
+				last := lunar.ToSolar(y, lunar.Date{Day: m.Days, Month: m.Month, Leap: m.Leap})
+				require.Equal(t, m.Start.AddDate(0, 0, m.Days-1), last)
+				if m.Days == 29 {
+					require.True(t, lunar.ToSolar(y, lunar.Date{Day: 30, Month: m.Month, Leap: m.Leap}).IsZero())
+				}
+			}
+		}
+	})
+}
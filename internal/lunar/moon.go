@@ -0,0 +1,36 @@
+package lunar
+
+import (
+	"math"
+	"time"
+)
+
+// NewMoons returns the instants, in UTC, of the new moons (sóc) from from up
+// to but not including to. These are the same new moons that start the lunar
+// months, accurate to a few minutes.
+func NewMoons(from, to time.Time) []time.Time {
+	return moonPhases(from, to, 0)
+}
+
+// FullMoons returns the instants, in UTC, of the full moons (vọng) from from
+// up to but not including to.
+func FullMoons(from, to time.Time) []time.Time {
+	return moonPhases(from, to, 0.5)
+}
+
+// moonPhases returns the instants of the phase, as a fraction of a lunation
+// after the new moon, in [from, to).
+func moonPhases(from, to time.Time, phase float64) []time.Time {
+	var instants []time.Time
+	k := math.Floor((julianDateOf(from)-2415021.076998695)/29.530588853) - 1
+	for ; ; k++ {
+		t := timeOfJulianDate(newMoon(k + phase))
+		if !t.Before(to) {
+			break
+		}
+		if !t.Before(from) {
+			instants = append(instants, t)
+		}
+	}
+	return instants
+}
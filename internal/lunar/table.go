@@ -0,0 +1,160 @@
+package lunar
+
+import (
+	"sync"
+	"time"
+)
+
+// month is one lunar month of a yearTable.
+type month struct {
+	month int
+	leap  bool
+	start int // Julian day number of day 1
+	days  int
+}
+
+// yearTable lists the months of a lunar year in order, from Tết up to the day
+// before the next Tết.
+type yearTable struct {
+	months []month
+}
+
+func (t *yearTable) find(m int, leap bool) (month, bool) {
+	for _, mo := range t.months {
+		if mo.month == m && mo.leap == leap {
+			return mo, true
+		}
+	}
+	return month{}, false
+}
+
+func (t *yearTable) start() int {
+	return t.months[0].start
+}
+
+func (t *yearTable) end() int {
+	last := t.months[len(t.months)-1]
+	return last.start + last.days
+}
+
+// meridian is the offset east of UTC, in fractions of a day, of the local
+// time new moons are dated in. A historical meridian changed once: new moons
+// before the Julian date (UT) switchAt were dated at before instead.
+type meridian struct {
+	offset   float64
+	before   float64
+	switchAt float64
+}
+
+// at returns the offset in effect at the Julian date jd (UT).
+func (m meridian) at(jd float64) float64 {
+	if jd < m.switchAt {
+		return m.before
+	}
+	return m.offset
+}
+
+type tableKey struct {
+	year     int
+	meridian meridian
+}
+
+var (
+	tablesMu sync.Mutex
+	tables   = map[tableKey]*yearTable{}
+)
+
+// tableFor returns the month table of the lunar year, computing it on first
+// use. Tables are cached per year and meridian, so repeated lookups only cost
+// a map access.
+func tableFor(year int, m meridian) *yearTable {
+	key := tableKey{year: year, meridian: m}
+
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+
+	if t, ok := tables[key]; ok {
+		return t
+	}
+	t := buildTable(year, m)
+	tables[key] = t
+	return t
+}
+
+func buildTable(year int, m meridian) *yearTable {
+	a11 := month11(year-1, m)
+	b11 := month11(year, m)
+	c11 := month11(year+1, m)
+
+	// The months from month 11 of the previous Gregorian year up to month 11
+	// of this one hold months 1 to 10 of the lunar year; months 11 and 12
+	// follow in the next span.
+	var months []month
+	started := false
+	for _, mo := range monthsBetween(a11, b11, m) {
+		if mo.month == 1 && !mo.leap {
+			started = true
+		}
+		if started {
+			months = append(months, mo)
+		}
+	}
+	for _, mo := range monthsBetween(b11, c11, m) {
+		if mo.month == 1 && !mo.leap {
+			break
+		}
+		months = append(months, mo)
+	}
+	return &yearTable{months: months}
+}
+
+// monthsBetween returns the months from the month 11 starting on a11 up to,
+// but not including, the month 11 starting on b11, numbered and with their
+// leap month marked.
+func monthsBetween(a11, b11 int, m meridian) []month {
+	k := newMoonIndex(a11)
+	count := 12
+	leapOffset := -1
+	if b11-a11 > 365 {
+		count = 13
+		leapOffset = leapMonthOffset(a11, m)
+	}
+
+	months := make([]month, 0, count)
+	start := a11
+	for i := 0; i < count; i++ {
+		next := b11
+		if i < count-1 {
+			next = newMoonDay(k+i+1, m)
+		}
+
+		number := i + 11
+		leap := false
+		if leapOffset >= 0 && i >= leapOffset {
+			number = i + 10
+			leap = i == leapOffset
+		}
+		if number > 12 {
+			number = (number-1)%12 + 1
+		}
+
+		months = append(months, month{month: number, leap: leap, start: start, days: next - start})
+		start = next
+	}
+	return months
+}
+
+// meridianFor returns the meridian used for the lunar year. Unless
+// WithHistoricalMeridian is used, it is the standard offset of the configured
+// zone in that year, so half and quarter hour zones such as Asia/Kolkata
+// (+5:30) and Asia/Kathmandu (+5:45) keep their exact offset and daylight
+// saving time does not move month boundaries.
+func (c *findConfig) meridianFor(year int) meridian {
+	if c.meridian != nil {
+		return *c.meridian
+	}
+	loc := c.location()
+	_, january := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, july := time.Date(year, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	return meridian{offset: float64(min(january, july)) / (24 * 60 * 60)}
+}